@@ -0,0 +1,142 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import (
+	"math"
+	"syscall/js"
+	"time"
+)
+
+// adjustBrightnessContrastWrapper wraps the adjustBrightnessContrast logic for syscall/js
+// interaction.
+// It expects imageData { width, height, data: Uint8ClampedArray }, a brightness delta
+// (-255 to 255), and a contrast factor (0 to 2, 1 leaves contrast unchanged).
+// It returns the processed Uint8ClampedArray or an error object.
+func adjustBrightnessContrastWrapper(this js.Value, args []js.Value) interface{} {
+	startTime := time.Now()
+	logDebug("adjustBrightnessContrastWrapper called")
+
+	if len(args) < 3 {
+		return createError("Invalid number of arguments for adjustBrightnessContrast: expected 3 (imageData, brightness, contrast)")
+	}
+	srcData, width, height, err := readImageData(args[0])
+	if err != nil {
+		return createError(err.Error())
+	}
+	if args[1].Type() != js.TypeNumber || args[1].Float() < -255 || args[1].Float() > 255 {
+		return createError("Invalid brightness argument: expected a number between -255 and 255")
+	}
+	if args[2].Type() != js.TypeNumber || args[2].Float() < 0 || args[2].Float() > 2 {
+		return createError("Invalid contrast argument: expected a number between 0 and 2")
+	}
+
+	resultData := adjustBrightnessContrast(srcData, width, height, args[1].Float(), args[2].Float())
+
+	logInfo("adjustBrightnessContrastWrapper completed in %v", time.Since(startTime))
+	return bytesToJS(resultData)
+}
+
+// adjustBrightnessContrast maps each RGB value v to (v-128)*contrast + 128 + brightness,
+// clamped to 0-255 (internal logic). Contrast pivots around mid-gray, so 0 flattens the
+// image to gray 128 (before the brightness shift) and 2 doubles every distance from it.
+// The mapping is precomputed as a lookup table; alpha is left unchanged.
+func adjustBrightnessContrast(srcData []uint8, width, height int, brightness, contrast float64) []uint8 {
+	logDebug("Adjusting brightness %.1f, contrast %.2f...", brightness, contrast)
+	var lut [256]uint8
+	for v := range lut {
+		lut[v] = uint8(clampFloat64((float64(v)-128)*contrast+128+brightness+0.5, 0, 255))
+	}
+	return applyChannelLUT(srcData, width, height, &lut)
+}
+
+// applyGammaWrapper wraps the applyGamma logic for syscall/js interaction.
+// It expects imageData { width, height, data: Uint8ClampedArray } and a gamma greater
+// than 0.
+// It returns the processed Uint8ClampedArray or an error object.
+func applyGammaWrapper(this js.Value, args []js.Value) interface{} {
+	startTime := time.Now()
+	logDebug("applyGammaWrapper called")
+
+	if len(args) < 2 {
+		return createError("Invalid number of arguments for applyGamma: expected 2 (imageData, gamma)")
+	}
+	srcData, width, height, err := readImageData(args[0])
+	if err != nil {
+		return createError(err.Error())
+	}
+	if args[1].Type() != js.TypeNumber || !(args[1].Float() > 0) {
+		return createError("Invalid gamma argument: expected a number greater than 0")
+	}
+
+	resultData := applyGamma(srcData, width, height, args[1].Float())
+
+	logInfo("applyGammaWrapper completed in %v", time.Since(startTime))
+	return bytesToJS(resultData)
+}
+
+// applyGamma maps each RGB value v to 255 * (v/255)^(1/gamma) (internal logic), so gamma
+// above 1 brightens midtones and below 1 darkens them; 0 and 255 are fixed. Applying gamma
+// and then 1/gamma round-trips up to 8-bit rounding, which is how callers can move into a
+// roughly linear space (gamma 1/2.2) and back. The curve is precomputed as a lookup table
+// so each value costs an index rather than a math.Pow; alpha is left unchanged.
+func applyGamma(srcData []uint8, width, height int, gamma float64) []uint8 {
+	logDebug("Applying gamma %.3f...", gamma)
+	var lut [256]uint8
+	for v := range lut {
+		lut[v] = uint8(clampFloat64(255*math.Pow(float64(v)/255, 1/gamma)+0.5, 0, 255))
+	}
+	return applyChannelLUT(srcData, width, height, &lut)
+}
+
+// adjustHSLWrapper wraps the adjustHSL logic for syscall/js interaction.
+// It expects imageData { width, height, data: Uint8ClampedArray }, a hue shift in degrees,
+// and saturation and lightness multipliers (non-negative; 1 leaves them unchanged).
+// It returns the processed Uint8ClampedArray or an error object.
+func adjustHSLWrapper(this js.Value, args []js.Value) interface{} {
+	startTime := time.Now()
+	logDebug("adjustHSLWrapper called")
+
+	if len(args) < 4 {
+		return createError("Invalid number of arguments for adjustHSL: expected 4 (imageData, hueShift, saturation, lightness)")
+	}
+	srcData, width, height, err := readImageData(args[0])
+	if err != nil {
+		return createError(err.Error())
+	}
+	if args[1].Type() != js.TypeNumber || math.IsNaN(args[1].Float()) || math.IsInf(args[1].Float(), 0) {
+		return createError("Invalid hueShift argument: expected a finite number of degrees")
+	}
+	if args[2].Type() != js.TypeNumber || !(args[2].Float() >= 0) {
+		return createError("Invalid saturation argument: expected a non-negative number")
+	}
+	if args[3].Type() != js.TypeNumber || !(args[3].Float() >= 0) {
+		return createError("Invalid lightness argument: expected a non-negative number")
+	}
+
+	resultData := adjustHSL(srcData, width, height, args[1].Float(), args[2].Float(), args[3].Float())
+
+	logInfo("adjustHSLWrapper completed in %v", time.Since(startTime))
+	return bytesToJS(resultData)
+}
+
+// adjustHSL rotates hue by hueShift degrees and scales saturation and lightness by the
+// given multipliers, each clamped to 0-1, in HSL space (internal logic). Grays have no hue,
+// so a hue shift leaves them unchanged and raising saturation cannot color them. Alpha is
+// left unchanged.
+func adjustHSL(srcData []uint8, width, height int, hueShift, saturation, lightness float64) []uint8 {
+	logDebug("Adjusting HSL: hue %+.1f°, saturation x%.2f, lightness x%.2f...", hueShift, saturation, lightness)
+	resultData := make([]uint8, len(srcData))
+	parallelRows(height, func(startY, endY int) {
+		for i := startY * width * 4; i < endY*width*4; i += 4 {
+			h, s, l := rgbToHSL(float64(srcData[i]), float64(srcData[i+1]), float64(srcData[i+2]))
+			r, g, b := hslToRGB(h+hueShift, clampFloat64(s*saturation, 0, 1), clampFloat64(l*lightness, 0, 1))
+			resultData[i] = uint8(clampFloat64(r+0.5, 0, 255))
+			resultData[i+1] = uint8(clampFloat64(g+0.5, 0, 255))
+			resultData[i+2] = uint8(clampFloat64(b+0.5, 0, 255))
+			resultData[i+3] = srcData[i+3]
+		}
+	})
+	return resultData
+}
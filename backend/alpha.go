@@ -0,0 +1,75 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import (
+	"fmt"
+	"sync/atomic"
+	"syscall/js"
+)
+
+// alphaPremultiplied records whether images exchanged with JavaScript carry premultiplied
+// alpha. Canvas ImageData is straight (the default), but some sources such as WebGL
+// readbacks and decoded bitmaps are premultiplied. All processing code works on straight
+// alpha: readImageData un-premultiplies incoming pixels and bytesToJS premultiplies results,
+// so blending, convolution, and resampling treat color correctly in either format.
+// It is atomic because calls from several workers may read it while another changes it.
+var alphaPremultiplied atomic.Bool
+
+// setAlphaFormatWrapper exposes setAlphaFormat to JavaScript.
+// It expects a format string ("straight" or "premultiplied") and returns null or an error object.
+func setAlphaFormatWrapper(this js.Value, args []js.Value) interface{} {
+	if len(args) < 1 || args[0].Type() != js.TypeString {
+		return createError("Invalid arguments for setAlphaFormat: expected 1 (format string)")
+	}
+	if err := setAlphaFormat(args[0].String()); err != nil {
+		return createError(err.Error())
+	}
+	return nil
+}
+
+// setAlphaFormat selects the alpha format of image data exchanged with JavaScript.
+func setAlphaFormat(format string) error {
+	switch format {
+	case "straight":
+		alphaPremultiplied.Store(false)
+	case "premultiplied":
+		alphaPremultiplied.Store(true)
+	default:
+		return fmt.Errorf("Invalid alpha format '%s': expected \"straight\" or \"premultiplied\"", format)
+	}
+	logInfo("Alpha format set to '%s'", format)
+	return nil
+}
+
+// unpremultiplyAlpha converts premultiplied RGBA to straight alpha in place. Fully
+// transparent pixels have no recoverable color and become transparent black.
+func unpremultiplyAlpha(data []uint8) {
+	for i := 0; i+3 < len(data); i += 4 {
+		a := int(data[i+3])
+		if a == 255 {
+			continue
+		}
+		for c := 0; c < 3; c++ {
+			if a == 0 {
+				data[i+c] = 0
+			} else {
+				data[i+c] = uint8(min((int(data[i+c])*255+a/2)/a, 255))
+			}
+		}
+	}
+}
+
+// premultiplyAlpha converts straight RGBA to premultiplied alpha in place.
+func premultiplyAlpha(data []uint8) {
+	for i := 0; i+3 < len(data); i += 4 {
+		a := int(data[i+3])
+		if a == 255 {
+			continue
+		}
+		for c := 0; c < 3; c++ {
+			data[i+c] = uint8((int(data[i+c])*a + 127) / 255)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import (
+	"bytes"
+	"syscall/js"
+	"testing"
+)
+
+// translucentImage returns seeded noise with alpha cycling through 64-255, so every pixel
+// keeps enough precision to survive premultiplication.
+func translucentImage(width, height int, seed int64) []uint8 {
+	data := randomImage(width, height, seed)
+	for i := 3; i < len(data); i += 4 {
+		data[i] = uint8(64 + (i/4*37)%192)
+	}
+	return data
+}
+
+func TestPremultipliedAlphaRoundTripAndComposite(t *testing.T) {
+	width, height := 12, 8
+	base, top := translucentImage(width, height, 1), translucentImage(width, height, 2)
+	defer setAlphaFormat("straight")
+
+	// Straight mode: the reference composite, exchanged as is
+	straight := jsBytes(blendImagesWrapper(js.Undefined(), []js.Value{
+		imageDataToJS(base, width, height), imageDataToJS(top, width, height)}))
+
+	if err := setAlphaFormat("premultiplied"); err != nil {
+		t.Fatal(err)
+	}
+	// Results leave premultiplied and come back straight
+	wire := imageDataToJS(base, width, height)
+	wantWire := append([]uint8(nil), base...)
+	premultiplyAlpha(wantWire)
+	if !bytes.Equal(jsBytes(wire.Get("data")), wantWire) {
+		t.Error("data handed to JavaScript is not premultiplied")
+	}
+	back, _, _, err := readImageData(wire)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := maxAbsDiff(back, base); diff > 2 {
+		t.Errorf("premultiplied round trip changed translucent pixels by %d", diff)
+	}
+
+	// Compositing premultiplied inputs gives the premultiplied form of the straight result
+	got := jsBytes(blendImagesWrapper(js.Undefined(), []js.Value{
+		imageDataToJS(base, width, height), imageDataToJS(top, width, height)}))
+	want := append([]uint8(nil), straight...)
+	premultiplyAlpha(want)
+	if diff := maxAbsDiff(got, want); diff > 2 {
+		t.Errorf("premultiplied composite differs from the straight one by %d", diff)
+	}
+
+	if err := setAlphaFormat("linear"); err == nil {
+		t.Error("unknown alpha format was accepted")
+	}
+}
@@ -0,0 +1,554 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"syscall/js"
+	"time"
+)
+
+// channelStats summarizes the distribution of a single channel's values.
+type channelStats struct {
+	Mean   float64
+	Min    float64
+	Max    float64
+	StdDev float64
+}
+
+// imageStatistics holds per-channel (R, G, B, A) and luminance statistics.
+type imageStatistics struct {
+	Channels  [4]channelStats
+	Luminance channelStats
+}
+
+// statsAccumulator collects the partial sums needed to derive channelStats.
+type statsAccumulator struct {
+	sum, sumSq float64
+	min, max   float64
+}
+
+func newStatsAccumulator() statsAccumulator {
+	return statsAccumulator{min: math.Inf(1), max: math.Inf(-1)}
+}
+
+func (a *statsAccumulator) add(v float64) {
+	a.sum += v
+	a.sumSq += v * v
+	if v < a.min {
+		a.min = v
+	}
+	if v > a.max {
+		a.max = v
+	}
+}
+
+func (a *statsAccumulator) merge(other statsAccumulator) {
+	a.sum += other.sum
+	a.sumSq += other.sumSq
+	a.min = math.Min(a.min, other.min)
+	a.max = math.Max(a.max, other.max)
+}
+
+// finish converts the accumulated sums over n samples into mean, min, max, and
+// (population) standard deviation.
+func (a *statsAccumulator) finish(n int) channelStats {
+	mean := a.sum / float64(n)
+	variance := a.sumSq/float64(n) - mean*mean
+	return channelStats{
+		Mean:   mean,
+		Min:    a.min,
+		Max:    a.max,
+		StdDev: math.Sqrt(math.Max(variance, 0)), // Guard against tiny negative rounding error
+	}
+}
+
+// luminance returns the Rec.601 luma of an RGB triple.
+func luminance(r, g, b uint8) float64 {
+	return 0.299*float64(r) + 0.587*float64(g) + 0.114*float64(b)
+}
+
+// imageStatsWrapper wraps the imageStats logic for syscall/js interaction.
+// It expects imageData { width, height, data: Uint8ClampedArray }.
+// It returns { r, g, b, a, luminance }, each { mean, min, max, stdDev }, or an error object.
+func imageStatsWrapper(this js.Value, args []js.Value) interface{} {
+	startTime := time.Now()
+	logDebug("imageStatsWrapper called")
+
+	if len(args) < 1 {
+		return createError("Invalid number of arguments for imageStats: expected 1 (imageData)")
+	}
+
+	srcData, width, height, err := readImageData(args[0])
+	if err != nil {
+		return createError(err.Error())
+	}
+
+	stats := imageStats(srcData, width, height)
+
+	toJS := func(s channelStats) js.Value {
+		obj := js.Global().Get("Object").New()
+		obj.Set("mean", s.Mean)
+		obj.Set("min", s.Min)
+		obj.Set("max", s.Max)
+		obj.Set("stdDev", s.StdDev)
+		return obj
+	}
+	result := js.Global().Get("Object").New()
+	for c, name := range []string{"r", "g", "b", "a"} {
+		result.Set(name, toJS(stats.Channels[c]))
+	}
+	result.Set("luminance", toJS(stats.Luminance))
+
+	logInfo("imageStatsWrapper completed in %v", time.Since(startTime))
+	return result
+}
+
+// imageStats computes per-channel and luminance statistics in a single parallel pass
+// (internal logic). Each row chunk accumulates its own partial sums and sums of squares,
+// which are merged once all chunks have finished, so no locking is needed.
+func imageStats(srcData []uint8, width, height int) imageStatistics {
+	chunkRows := currentChunkRows()
+	partials := make([][5]statsAccumulator, rowChunkCount(height, chunkRows))
+
+	parallelRowsSized(height, chunkRows, func(startY, endY int) {
+		var acc [5]statsAccumulator
+		for c := range acc {
+			acc[c] = newStatsAccumulator()
+		}
+		for y := startY; y < endY; y++ {
+			for x := 0; x < width; x++ {
+				idx := (y*width + x) * 4
+				for c := 0; c < 4; c++ {
+					acc[c].add(float64(srcData[idx+c]))
+				}
+				acc[4].add(luminance(srcData[idx], srcData[idx+1], srcData[idx+2]))
+			}
+		}
+		partials[startY/chunkRows] = acc
+	})
+
+	// Merge the per-chunk partial results
+	var total [5]statsAccumulator
+	for c := range total {
+		total[c] = newStatsAccumulator()
+	}
+	for _, partial := range partials {
+		for c := range total {
+			total[c].merge(partial[c])
+		}
+	}
+
+	n := width * height
+	var stats imageStatistics
+	for c := 0; c < 4; c++ {
+		stats.Channels[c] = total[c].finish(n)
+	}
+	stats.Luminance = total[4].finish(n)
+	return stats
+}
+
+// computeIntegralImageWrapper wraps the computeIntegralImage logic for syscall/js interaction.
+// It expects imageData { width, height, data: Uint8ClampedArray }.
+// It returns { width, height, data: Float64Array } or an error object, where data[y*width+x]
+// is the sum of luminance over the rectangle from (0, 0) to (x, y) inclusive.
+func computeIntegralImageWrapper(this js.Value, args []js.Value) interface{} {
+	startTime := time.Now()
+	logDebug("computeIntegralImageWrapper called")
+
+	if len(args) < 1 {
+		return createError("Invalid number of arguments for computeIntegralImage: expected 1 (imageData)")
+	}
+	srcData, width, height, err := readImageData(args[0])
+	if err != nil {
+		return createError(err.Error())
+	}
+
+	table := computeIntegralImage(lumaPlane(srcData, width, height), width, height)
+
+	result := js.Global().Get("Object").New()
+	result.Set("width", width)
+	result.Set("height", height)
+	result.Set("data", float64sToJS(table))
+
+	logInfo("computeIntegralImageWrapper completed in %v", time.Since(startTime))
+	return result
+}
+
+// computeProfilesWrapper wraps the computeProfiles logic for syscall/js interaction.
+// It expects imageData { width, height, data: Uint8ClampedArray } and an optional
+// statistic string ("mean", the default, or "sum").
+// It returns { rows: Float64Array, columns: Float64Array } or an error object.
+func computeProfilesWrapper(this js.Value, args []js.Value) interface{} {
+	startTime := time.Now()
+	logDebug("computeProfilesWrapper called")
+
+	if len(args) < 1 {
+		return createError("Invalid number of arguments for computeProfiles: expected 1 (imageData)")
+	}
+	srcData, width, height, err := readImageData(args[0])
+	if err != nil {
+		return createError(err.Error())
+	}
+	mean := true
+	if len(args) > 1 && !args[1].IsUndefined() {
+		switch args[1].String() {
+		case "mean":
+		case "sum":
+			mean = false
+		default:
+			return createError(fmt.Sprintf("Invalid statistic '%s': expected \"mean\" or \"sum\"", args[1].String()))
+		}
+	}
+
+	rows, columns := computeProfiles(lumaPlane(srcData, width, height), width, height, mean)
+
+	result := js.Global().Get("Object").New()
+	result.Set("rows", float64sToJS(rows))
+	result.Set("columns", float64sToJS(columns))
+
+	logInfo("computeProfilesWrapper completed in %v", time.Since(startTime))
+	return result
+}
+
+// computeProfiles projects a width x height plane onto each axis (internal logic):
+// rows[y] is the sum of row y and columns[x] the sum of column x, or their means when
+// `mean` is set. Row chunks are processed in parallel; each chunk produces its rows'
+// sums directly and its own partial column sums, which are added up after the join in
+// chunk order, so the result does not depend on scheduling.
+func computeProfiles(plane []float64, width, height int, mean bool) ([]float64, []float64) {
+	rows := make([]float64, height)
+	chunkRows := currentChunkRows()
+	partials := make([][]float64, rowChunkCount(height, chunkRows))
+	parallelRowsSized(height, chunkRows, func(startY, endY int) {
+		partial := make([]float64, width)
+		for y := startY; y < endY; y++ {
+			sum := 0.0
+			for x, v := range plane[y*width : (y+1)*width] {
+				sum += v
+				partial[x] += v
+			}
+			rows[y] = sum
+		}
+		partials[startY/chunkRows] = partial
+	})
+
+	columns := make([]float64, width)
+	for _, partial := range partials {
+		for x, v := range partial {
+			columns[x] += v
+		}
+	}
+
+	if mean {
+		for y := range rows {
+			rows[y] /= float64(width)
+		}
+		for x := range columns {
+			columns[x] /= float64(height)
+		}
+	}
+	return rows, columns
+}
+
+// computeIntegralImage builds the summed-area table of a width x height plane (internal
+// logic): table[y*width+x] is the sum of plane over [0, x] x [0, y], so any rectangle sum
+// takes four lookups. The prefix sum runs in two passes that each parallelize along the
+// other axis: every row is prefix-summed horizontally (rows independent), then every column
+// accumulates down the rows (columns independent, rows serial within a column).
+func computeIntegralImage(plane []float64, width, height int) []float64 {
+	table := make([]float64, len(plane))
+	parallelRows(height, func(startY, endY int) {
+		for y := startY; y < endY; y++ {
+			sum := 0.0
+			for x := 0; x < width; x++ {
+				sum += plane[y*width+x]
+				table[y*width+x] = sum
+			}
+		}
+	})
+	parallelRows(width, func(startX, endX int) {
+		for y := 1; y < height; y++ {
+			row, prev := table[y*width:(y+1)*width], table[(y-1)*width:y*width]
+			for x := startX; x < endX; x++ {
+				row[x] += prev[x]
+			}
+		}
+	})
+	return table
+}
+
+// computeHistogramWrapper counts an image's R, G, B, and luminance values into 256-bin
+// histograms (see channelHistograms).
+// It expects imageData { width, height, data: Uint8ClampedArray }.
+// It returns { r, g, b, luminance }, each a 256-bin Uint32Array of pixel counts, or an
+// error object.
+func computeHistogramWrapper(this js.Value, args []js.Value) interface{} {
+	startTime := time.Now()
+	logDebug("computeHistogramWrapper called")
+
+	if len(args) < 1 {
+		return createError("Invalid number of arguments for computeHistogram: expected 1 (imageData)")
+	}
+	srcData, width, height, err := readImageData(args[0])
+	if err != nil {
+		return createError(err.Error())
+	}
+
+	hists := channelHistograms(srcData, width, height)
+
+	result := js.Global().Get("Object").New()
+	bins := make([]uint32, 256)
+	channels := []struct {
+		index int
+		name  string
+	}{{0, "r"}, {1, "g"}, {2, "b"}, {4, "luminance"}}
+	for _, ch := range channels {
+		for v, n := range hists[ch.index] {
+			bins[v] = uint32(n)
+		}
+		result.Set(ch.name, uint32sToJS(bins))
+	}
+
+	logInfo("computeHistogramWrapper completed in %v", time.Since(startTime))
+	return result
+}
+
+// channelHistograms counts the 256 value bins of R, G, B, A, and rounded luminance (index 4).
+// Row chunks fill their own partial histograms, which are summed at the end.
+func channelHistograms(srcData []uint8, width, height int) [5][256]int {
+	chunkRows := currentChunkRows()
+	partials := make([][5][256]int, rowChunkCount(height, chunkRows))
+
+	parallelRowsSized(height, chunkRows, func(startY, endY int) {
+		hist := &partials[startY/chunkRows]
+		for i := startY * width * 4; i < endY*width*4; i += 4 {
+			for c := 0; c < 4; c++ {
+				hist[c][srcData[i+c]]++
+			}
+			l := int(luminance(srcData[i], srcData[i+1], srcData[i+2]) + 0.5)
+			hist[4][clamp(l, 0, 255)]++
+		}
+	})
+
+	var total [5][256]int
+	for _, partial := range partials {
+		for c := range total {
+			for v, n := range partial[c] {
+				total[c][v] += n
+			}
+		}
+	}
+	return total
+}
+
+// histogramEntropy returns the Shannon entropy, in bits, of the distribution described by
+// a histogram. An empty or single-valued histogram has entropy 0.
+func histogramEntropy(hist *[256]int) float64 {
+	total := 0
+	for _, n := range hist {
+		total += n
+	}
+	entropy := 0.0
+	for _, n := range hist {
+		if n > 0 {
+			p := float64(n) / float64(total)
+			entropy -= p * math.Log2(p)
+		}
+	}
+	return entropy
+}
+
+// imageEntropyWrapper wraps the imageEntropy logic for syscall/js interaction.
+// It expects imageData { width, height, data: Uint8ClampedArray }.
+// It returns { r, g, b, a, luminance } entropies in bits per pixel, or an error object.
+func imageEntropyWrapper(this js.Value, args []js.Value) interface{} {
+	startTime := time.Now()
+	logDebug("imageEntropyWrapper called")
+
+	if len(args) < 1 {
+		return createError("Invalid number of arguments for imageEntropy: expected 1 (imageData)")
+	}
+	srcData, width, height, err := readImageData(args[0])
+	if err != nil {
+		return createError(err.Error())
+	}
+
+	entropies := imageEntropy(srcData, width, height)
+
+	result := js.Global().Get("Object").New()
+	for c, name := range []string{"r", "g", "b", "a", "luminance"} {
+		result.Set(name, entropies[c])
+	}
+
+	logInfo("imageEntropyWrapper completed in %v", time.Since(startTime))
+	return result
+}
+
+// imageEntropy computes the Shannon entropy of the R, G, B, A, and luminance histograms
+// (internal logic), in bits per pixel. Values near 0 indicate flat content that compresses
+// well; 8 is the maximum for 256 equally likely values.
+func imageEntropy(srcData []uint8, width, height int) [5]float64 {
+	hists := channelHistograms(srcData, width, height)
+	var entropies [5]float64
+	for c := range hists {
+		entropies[c] = histogramEntropy(&hists[c])
+	}
+	return entropies
+}
+
+// blob describes one connected component found by findBlobs.
+type blob struct {
+	MinX, MinY, MaxX, MaxY int
+	Area                   int
+	CentroidX, CentroidY   float64
+}
+
+// findBlobsWrapper wraps the findBlobs logic for syscall/js interaction.
+// It expects imageData { width, height, data: Uint8ClampedArray }, a luminance threshold
+// (0-255), a minimum blob size in pixels, and an optional connectivity (4 or 8, default 8).
+// It returns an array of { x, y, width, height, area, centroidX, centroidY } or an error object.
+func findBlobsWrapper(this js.Value, args []js.Value) interface{} {
+	startTime := time.Now()
+	logDebug("findBlobsWrapper called")
+
+	if len(args) < 3 {
+		return createError("Invalid number of arguments for findBlobs: expected 3 (imageData, threshold, minSize)")
+	}
+	srcData, width, height, err := readImageData(args[0])
+	if err != nil {
+		return createError(err.Error())
+	}
+	if args[1].Type() != js.TypeNumber || args[1].Float() < 0 || args[1].Float() > 255 {
+		return createError("Invalid threshold argument: expected a number between 0 and 255")
+	}
+	if args[2].Type() != js.TypeNumber || args[2].Int() < 1 {
+		return createError("Invalid minSize argument: expected a positive number")
+	}
+	connectivity := 8
+	if len(args) > 3 && !args[3].IsUndefined() {
+		if args[3].Type() != js.TypeNumber || (args[3].Int() != 4 && args[3].Int() != 8) {
+			return createError("Invalid connectivity argument: expected 4 or 8")
+		}
+		connectivity = args[3].Int()
+	}
+
+	blobs := findBlobs(srcData, width, height, args[1].Float(), args[2].Int(), connectivity)
+
+	result := js.Global().Get("Array").New()
+	for _, b := range blobs {
+		obj := js.Global().Get("Object").New()
+		obj.Set("x", b.MinX)
+		obj.Set("y", b.MinY)
+		obj.Set("width", b.MaxX-b.MinX+1)
+		obj.Set("height", b.MaxY-b.MinY+1)
+		obj.Set("area", b.Area)
+		obj.Set("centroidX", b.CentroidX)
+		obj.Set("centroidY", b.CentroidY)
+		result.Call("push", obj)
+	}
+
+	logInfo("findBlobsWrapper completed in %v (%d blobs)", time.Since(startTime), len(blobs))
+	return result
+}
+
+// findBlobs labels the connected components of the pixels whose luminance is at least
+// `threshold` (internal logic). A first raster pass gives every foreground pixel the label of
+// an already-visited neighbor (left and up, plus the upper diagonals for 8-connectivity),
+// recording label equivalences in a union-find; a second pass accumulates the bounding box,
+// area, and centroid of each resolved component. Components smaller than minSize pixels are
+// dropped. Blobs are returned in the order their first pixel is met in raster order.
+func findBlobs(srcData []uint8, width, height int, threshold float64, minSize, connectivity int) []blob {
+	luma := lumaPlane(srcData, width, height)
+	labels := make([]int, width*height) // 0 is background
+	parent := []int{0}
+
+	find := func(l int) int {
+		for parent[l] != l {
+			parent[l] = parent[parent[l]] // Path halving
+			l = parent[l]
+		}
+		return l
+	}
+	union := func(a, b int) int {
+		ra, rb := find(a), find(b)
+		if ra < rb {
+			parent[rb] = ra
+			return ra
+		}
+		parent[ra] = rb
+		return rb
+	}
+
+	neighbors := [][2]int{{-1, 0}, {0, -1}}
+	if connectivity == 8 {
+		neighbors = append(neighbors, [2]int{-1, -1}, [2]int{1, -1})
+	}
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			i := y*width + x
+			if luma[i] < threshold {
+				continue
+			}
+			label := 0
+			for _, d := range neighbors {
+				nx, ny := x+d[0], y+d[1]
+				if nx < 0 || ny < 0 || nx >= width {
+					continue
+				}
+				if n := labels[ny*width+nx]; n != 0 {
+					if label == 0 {
+						label = find(n)
+					} else {
+						label = union(label, n)
+					}
+				}
+			}
+			if label == 0 {
+				label = len(parent)
+				parent = append(parent, label)
+			}
+			labels[i] = label
+		}
+	}
+
+	index := map[int]int{} // Root label -> position in blobs
+	var blobs []blob
+	var sumX, sumY []float64
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			l := labels[y*width+x]
+			if l == 0 {
+				continue
+			}
+			root := find(l)
+			k, ok := index[root]
+			if !ok {
+				k = len(blobs)
+				index[root] = k
+				blobs = append(blobs, blob{MinX: x, MinY: y, MaxX: x, MaxY: y})
+				sumX = append(sumX, 0)
+				sumY = append(sumY, 0)
+			}
+			b := &blobs[k]
+			b.MinX, b.MaxX = min(b.MinX, x), max(b.MaxX, x)
+			b.MaxY = y
+			b.Area++
+			sumX[k] += float64(x)
+			sumY[k] += float64(y)
+		}
+	}
+
+	kept := blobs[:0]
+	for k, b := range blobs {
+		if b.Area < minSize {
+			continue
+		}
+		b.CentroidX = sumX[k] / float64(b.Area)
+		b.CentroidY = sumY[k] / float64(b.Area)
+		kept = append(kept, b)
+	}
+	return kept
+}
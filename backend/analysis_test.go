@@ -0,0 +1,183 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"syscall/js"
+	"testing"
+)
+
+func TestImageStats(t *testing.T) {
+	// Alternate rows of two colors, so every statistic is known in closed form
+	width, height := 10, 150
+	a, b := [4]uint8{0, 10, 20, 255}, [4]uint8{100, 50, 20, 128}
+	data := make([]uint8, width*height*4)
+	for y := 0; y < height; y++ {
+		c := a
+		if y%2 == 1 {
+			c = b
+		}
+		copy(data[y*width*4:], solidImage(width, 1, c))
+	}
+
+	setConcurrency(3, 7) // Several uneven chunks to merge
+	defer setConcurrency(0, 0)
+	stats := imageStats(data, width, height)
+
+	check := func(name string, got channelStats, lo, hi float64) {
+		t.Helper()
+		want := channelStats{Mean: (lo + hi) / 2, Min: math.Min(lo, hi), Max: math.Max(lo, hi), StdDev: math.Abs(hi-lo) / 2}
+		if math.Abs(got.Mean-want.Mean) > 1e-9 || got.Min != want.Min || got.Max != want.Max || math.Abs(got.StdDev-want.StdDev) > 1e-6 {
+			t.Errorf("%s: got %+v, want %+v", name, got, want)
+		}
+	}
+	for c, name := range []string{"r", "g", "b", "a"} {
+		check(name, stats.Channels[c], float64(a[c]), float64(b[c]))
+	}
+	check("luminance", stats.Luminance, luminance(a[0], a[1], a[2]), luminance(b[0], b[1], b[2]))
+}
+
+func TestComputeIntegralImage(t *testing.T) {
+	plane := []float64{
+		1, 2, 3, 4,
+		5, 6, 7, 8,
+		9, 10, 11, 12,
+	}
+	want := []float64{
+		1, 3, 6, 10,
+		6, 14, 24, 36,
+		15, 33, 54, 78,
+	}
+	setConcurrency(3, 1)
+	defer setConcurrency(0, 0)
+	got := computeIntegralImage(plane, 4, 3)
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("table = %v, want %v", got, want)
+		}
+	}
+
+	// Any rectangle sum takes four lookups: [1, 2] x [1, 2] is 6+7+10+11
+	if sum := got[2*4+2] - got[0*4+2] - got[2*4+0] + got[0*4+0]; sum != 34 {
+		t.Errorf("rectangle sum %v, want 34", sum)
+	}
+}
+
+func TestFindBlobs(t *testing.T) {
+	width, height := 20, 12
+	data := solidImage(width, height, [4]uint8{0, 0, 0, 255})
+	set := func(x0, y0, x1, y1 int) {
+		for y := y0; y <= y1; y++ {
+			for x := x0; x <= x1; x++ {
+				copy(data[(y*width+x)*4:], []uint8{255, 255, 255, 255})
+			}
+		}
+	}
+	set(2, 2, 4, 4)   // A 3x3 square
+	set(15, 3, 15, 5) // A U shape, whose arms get separate labels until the bottom row
+	set(17, 3, 17, 5)
+	set(16, 5, 16, 5)
+	set(12, 7, 13, 8) // A 2x2 square
+	set(6, 9, 6, 9)   // Two diagonal neighbors
+	set(7, 10, 7, 10)
+	set(18, 1, 18, 1) // A speck below minSize
+
+	want := []blob{
+		{MinX: 2, MinY: 2, MaxX: 4, MaxY: 4, Area: 9, CentroidX: 3, CentroidY: 3},
+		{MinX: 15, MinY: 3, MaxX: 17, MaxY: 5, Area: 7, CentroidX: 16, CentroidY: 29.0 / 7},
+		{MinX: 12, MinY: 7, MaxX: 13, MaxY: 8, Area: 4, CentroidX: 12.5, CentroidY: 7.5},
+		{MinX: 6, MinY: 9, MaxX: 7, MaxY: 10, Area: 2, CentroidX: 6.5, CentroidY: 9.5},
+	}
+	for _, connectivity := range []int{8, 4} {
+		got := findBlobs(data, width, height, 128, 2, connectivity)
+		if connectivity == 4 {
+			want = want[:3] // The diagonal pair splits into two specks
+		}
+		if len(got) != len(want) {
+			t.Fatalf("%d-connectivity: found %d blobs %+v, want %d", connectivity, len(got), got, len(want))
+		}
+		for i := range want {
+			g, w := got[i], want[i]
+			if g.MinX != w.MinX || g.MinY != w.MinY || g.MaxX != w.MaxX || g.MaxY != w.MaxY || g.Area != w.Area ||
+				math.Abs(g.CentroidX-w.CentroidX) > 1e-9 || math.Abs(g.CentroidY-w.CentroidY) > 1e-9 {
+				t.Errorf("%d-connectivity: blob %d is %+v, want %+v", connectivity, i, g, w)
+			}
+		}
+	}
+}
+
+func TestComputeProfiles(t *testing.T) {
+	// One bright row (y = 2) in a dark 5x4 plane
+	width, height := 5, 4
+	plane := make([]float64, width*height)
+	for x := 0; x < width; x++ {
+		plane[2*width+x] = 200
+	}
+	setConcurrency(4, 1)
+	defer setConcurrency(0, 0)
+
+	rows, columns := computeProfiles(plane, width, height, false)
+	if want := []float64{0, 0, 1000, 0}; fmt.Sprint(rows) != fmt.Sprint(want) {
+		t.Errorf("row sums %v, want %v", rows, want)
+	}
+	if want := []float64{200, 200, 200, 200, 200}; fmt.Sprint(columns) != fmt.Sprint(want) {
+		t.Errorf("column sums %v, want %v", columns, want)
+	}
+
+	rows, columns = computeProfiles(plane, width, height, true)
+	if want := []float64{0, 0, 200, 0}; fmt.Sprint(rows) != fmt.Sprint(want) {
+		t.Errorf("row means %v, want %v", rows, want)
+	}
+	if want := []float64{50, 50, 50, 50, 50}; fmt.Sprint(columns) != fmt.Sprint(want) {
+		t.Errorf("column means %v, want %v", columns, want)
+	}
+}
+
+func TestChannelHistograms(t *testing.T) {
+	// Row y has red y and alpha 255-y over a constant green and blue
+	width, height := 10, 150
+	data := make([]uint8, width*height*4)
+	for y := 0; y < height; y++ {
+		copy(data[y*width*4:], solidImage(width, 1, [4]uint8{uint8(y), 77, 0, uint8(255 - y)}))
+	}
+	setConcurrency(3, 7) // Uneven chunks whose partial histograms must all be merged
+	defer setConcurrency(0, 0)
+
+	hists := channelHistograms(data, width, height)
+	for c, name := range []string{"r", "g", "b", "a", "luminance"} {
+		sum := 0
+		for _, n := range hists[c] {
+			sum += n
+		}
+		if sum != width*height {
+			t.Errorf("%s bins sum to %d, want %d", name, sum, width*height)
+		}
+	}
+	for v := 0; v < 256; v++ {
+		want := 0
+		if v < height {
+			want = width
+		}
+		if hists[0][v] != want || hists[3][255-v] != want {
+			t.Fatalf("bin %d: red %d, alpha (at %d) %d, want %d each", v, hists[0][v], 255-v, hists[3][255-v], want)
+		}
+	}
+	if hists[1][77] != width*height || hists[2][0] != width*height {
+		t.Errorf("constant green and blue are spread over several bins")
+	}
+
+	result := computeHistogramWrapper(js.Undefined(), []js.Value{imageDataToJS(data, width, height)}).(js.Value)
+	for _, name := range []string{"r", "g", "b", "luminance"} {
+		bins := result.Get(name)
+		sum := 0
+		for v := 0; v < bins.Length(); v++ {
+			sum += bins.Index(v).Int()
+		}
+		if bins.Length() != 256 || sum != width*height {
+			t.Errorf("%s: %d bins summing to %d, want 256 summing to %d", name, bins.Length(), sum, width*height)
+		}
+	}
+}
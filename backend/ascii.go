@@ -0,0 +1,86 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import (
+	"math"
+	"strings"
+	"syscall/js"
+	"time"
+)
+
+// defaultASCIIRamp orders characters from darkest to lightest as they appear as dark text
+// on a light background. For light-on-dark output (most terminals) pass it reversed.
+const defaultASCIIRamp = "@%#*+=-:. "
+
+// asciiCellAspect is the height-to-width ratio of a character cell; rows are scaled by it
+// so the art keeps the image's proportions.
+const asciiCellAspect = 2.0
+
+// toASCIIWrapper wraps the toASCII logic for syscall/js interaction.
+// It expects imageData { width, height, data: Uint8ClampedArray }, the number of columns,
+// and an optional ramp string ordered darkest to lightest (default "@%#*+=-:. ").
+// It returns the art as a string with one line per row, or an error object.
+func toASCIIWrapper(this js.Value, args []js.Value) interface{} {
+	startTime := time.Now()
+	logDebug("toASCIIWrapper called")
+
+	if len(args) < 2 {
+		return createError("Invalid number of arguments for toASCII: expected 2 (imageData, cols)")
+	}
+	srcData, width, height, err := readImageData(args[0])
+	if err != nil {
+		return createError(err.Error())
+	}
+	if args[1].Type() != js.TypeNumber || args[1].Int() < 1 {
+		return createError("Invalid cols argument: expected a positive number")
+	}
+	ramp := defaultASCIIRamp
+	if len(args) > 2 && !args[2].IsUndefined() && !args[2].IsNull() {
+		if args[2].Type() != js.TypeString || args[2].String() == "" {
+			return createError("Invalid ramp argument: expected a non-empty string")
+		}
+		ramp = args[2].String()
+	}
+
+	art := toASCII(srcData, width, height, args[1].Int(), ramp)
+
+	logInfo("toASCIIWrapper completed in %v", time.Since(startTime))
+	return art
+}
+
+// toASCII renders the image as text (internal logic). The image is divided into a grid of
+// cols columns (at most one per pixel) and as many rows as keep the aspect ratio given
+// asciiCellAspect; each cell's luminance is averaged over the pixels it covers and mapped
+// linearly onto the ramp, whose first character stands for black and last for white.
+// Alpha is ignored.
+func toASCII(srcData []uint8, width, height, cols int, ramp string) string {
+	cols = min(cols, width)
+	rows := clamp(int(math.Round(float64(height)*float64(cols)/float64(width)/asciiCellAspect)), 1, height)
+	chars := []rune(ramp)
+	logDebug("Rendering %dx%d image as %dx%d ASCII art", width, height, cols, rows)
+
+	lines := make([]string, rows)
+	parallelRows(rows, func(startRow, endRow int) {
+		var sb strings.Builder
+		for row := startRow; row < endRow; row++ {
+			y0, y1 := row*height/rows, (row+1)*height/rows
+			sb.Reset()
+			for col := 0; col < cols; col++ {
+				x0, x1 := col*width/cols, (col+1)*width/cols
+				sum := 0.0
+				for y := y0; y < y1; y++ {
+					for x := x0; x < x1; x++ {
+						idx := (y*width + x) * 4
+						sum += luminance(srcData[idx], srcData[idx+1], srcData[idx+2])
+					}
+				}
+				mean := sum / float64((x1-x0)*(y1-y0))
+				sb.WriteRune(chars[clamp(int(mean/256*float64(len(chars))), 0, len(chars)-1)])
+			}
+			lines[row] = sb.String()
+		}
+	})
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,22 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import "testing"
+
+func TestToASCII(t *testing.T) {
+	src := splitImage(8, 4, 4, [4]uint8{0, 0, 0, 255}, [4]uint8{255, 255, 255, 255})
+	if got, want := toASCII(src, 8, 4, 8, defaultASCIIRamp), "@@@@    \n@@@@    "; got != want {
+		t.Errorf("default ramp gave %q, want %q", got, want)
+	}
+	// More columns than pixels are clamped; multi-byte ramps work per character
+	if got, want := toASCII(src, 8, 4, 100, "█▒░"), "████░░░░\n████░░░░"; got != want {
+		t.Errorf("custom ramp gave %q, want %q", got, want)
+	}
+
+	gray := solidImage(16, 8, [4]uint8{160, 160, 160, 255})
+	if got, want := toASCII(gray, 16, 8, 4, "0123"), "2222"; got != want {
+		t.Errorf("light gray gave %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,160 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"syscall/js"
+	"time"
+)
+
+// blendOptions holds the optional settings accepted by blendImages.
+type blendOptions struct {
+	// Mode is the color blend function: "normal", "multiply", "screen", or "overlay".
+	Mode string
+	// Opacity scales the top layer's alpha, 0-1.
+	Opacity float64
+	// AlphaMode selects how the output alpha is computed: "source-over" composites the
+	// layers (aOut = aSrc + aDst*(1-aSrc)), "keep-base" keeps the base image's alpha, and
+	// "max" takes the larger of the two.
+	AlphaMode string
+}
+
+var (
+	blendModes      = []string{"normal", "multiply", "screen", "overlay"}
+	blendAlphaModes = []string{"source-over", "keep-base", "max"}
+)
+
+// readBlendOptions parses the optional blendImages options object
+// { mode?: string, opacity?: number, alphaMode?: string }. Undefined or null yields the
+// defaults (normal, opacity 1, source-over).
+func readBlendOptions(optionsJS js.Value) (blendOptions, error) {
+	opts := blendOptions{Mode: "normal", Opacity: 1, AlphaMode: "source-over"}
+	if optionsJS.IsUndefined() || optionsJS.IsNull() {
+		return opts, nil
+	}
+	if optionsJS.Type() != js.TypeObject {
+		return opts, errors.New("Invalid options argument: expected an object")
+	}
+
+	if m := optionsJS.Get("mode"); !m.IsUndefined() {
+		if m.Type() != js.TypeString || !containsString(blendModes, m.String()) {
+			return opts, fmt.Errorf("Invalid blend mode: expected one of %v", blendModes)
+		}
+		opts.Mode = m.String()
+	}
+	if o := optionsJS.Get("opacity"); !o.IsUndefined() {
+		if o.Type() != js.TypeNumber || o.Float() < 0 || o.Float() > 1 {
+			return opts, errors.New("Invalid opacity: expected a number between 0 and 1")
+		}
+		opts.Opacity = o.Float()
+	}
+	if a := optionsJS.Get("alphaMode"); !a.IsUndefined() {
+		if a.Type() != js.TypeString || !containsString(blendAlphaModes, a.String()) {
+			return opts, fmt.Errorf("Invalid alphaMode: expected one of %v", blendAlphaModes)
+		}
+		opts.AlphaMode = a.String()
+	}
+	return opts, nil
+}
+
+// blendImagesWrapper wraps the blendImages logic for syscall/js interaction.
+// It expects the base and top imageData objects { width, height, data: Uint8ClampedArray }
+// (same dimensions) and an optional options object { mode, opacity, alphaMode }.
+// It returns the blended Uint8ClampedArray or an error object.
+func blendImagesWrapper(this js.Value, args []js.Value) interface{} {
+	startTime := time.Now()
+	logDebug("blendImagesWrapper called")
+
+	if len(args) < 2 {
+		return createError("Invalid number of arguments for blendImages: expected 2 (baseImageData, topImageData)")
+	}
+	baseData, width, height, err := readImageData(args[0])
+	if err != nil {
+		return createError(err.Error())
+	}
+	topData, topWidth, topHeight, err := readImageData(args[1])
+	if err != nil {
+		return createError(err.Error())
+	}
+	if topWidth != width || topHeight != height {
+		return createError(fmt.Sprintf("Image dimensions do not match: %dx%d and %dx%d", width, height, topWidth, topHeight))
+	}
+	var optionsJS js.Value
+	if len(args) > 2 {
+		optionsJS = args[2]
+	}
+	opts, err := readBlendOptions(optionsJS)
+	if err != nil {
+		return createError(err.Error())
+	}
+
+	resultData := blendImages(baseData, topData, width, height, opts)
+
+	logInfo("blendImagesWrapper completed in %v", time.Since(startTime))
+	return bytesToJS(resultData)
+}
+
+// blendChannel applies the blend mode to normalized base (b) and top (s) values.
+func blendChannel(mode string, b, s float64) float64 {
+	switch mode {
+	case "multiply":
+		return b * s
+	case "screen":
+		return b + s - b*s
+	case "overlay":
+		if b <= 0.5 {
+			return 2 * b * s
+		}
+		return 1 - 2*(1-b)*(1-s)
+	default:
+		return s
+	}
+}
+
+// blendImages composites the top image over the base image (internal logic). The blend
+// mode combines the two colors, and the top layer's alpha (scaled by opacity) decides how
+// much of the blended color shows. With "source-over" the layers are composited as in the
+// W3C compositing model, so a transparent base shows the top colors and the output alpha is
+// aSrc + aDst*(1-aSrc). "keep-base" and "max" mix the colors by the top alpha alone and
+// keep the base alpha or the larger alpha respectively.
+func blendImages(baseData, topData []uint8, width, height int, opts blendOptions) []uint8 {
+	resultData := make([]uint8, len(baseData))
+	parallelRows(height, func(startY, endY int) {
+		for i := startY * width * 4; i < endY*width*4; i += 4 {
+			aDst := float64(baseData[i+3]) / 255
+			aSrc := float64(topData[i+3]) / 255 * opts.Opacity
+
+			var aOut float64
+			switch opts.AlphaMode {
+			case "keep-base":
+				aOut = aDst
+			case "max":
+				aOut = math.Max(aDst, aSrc)
+			default:
+				aOut = aSrc + aDst*(1-aSrc)
+			}
+
+			for c := 0; c < 3; c++ {
+				b := float64(baseData[i+c]) / 255
+				s := float64(topData[i+c]) / 255
+				var out float64
+				if opts.AlphaMode == "source-over" {
+					if aOut > 0 {
+						// The blend result only applies where the base is present
+						mixed := (1-aDst)*s + aDst*blendChannel(opts.Mode, b, s)
+						out = (aSrc*mixed + aDst*(1-aSrc)*b) / aOut
+					}
+				} else {
+					out = b + (blendChannel(opts.Mode, b, s)-b)*aSrc
+				}
+				resultData[i+c] = uint8(clampFloat64(out*255+0.5, 0, 255))
+			}
+			resultData[i+3] = uint8(clampFloat64(aOut*255+0.5, 0, 255))
+		}
+	})
+	return resultData
+}
@@ -0,0 +1,34 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import "testing"
+
+func TestBlendImagesAlphaModes(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		base, top [4]uint8
+		opts      blendOptions
+		want      [4]uint8
+	}{
+		// aOut = 0.251 + 0.502*(1-0.251) = 0.627; each color is the premultiplied sum
+		// aSrc*s + aDst*(1-aSrc)*b divided back by aOut
+		{"source-over", [4]uint8{200, 100, 0, 128}, [4]uint8{0, 0, 255, 64},
+			blendOptions{Mode: "normal", Opacity: 1, AlphaMode: "source-over"}, [4]uint8{120, 60, 102, 160}},
+		{"source-over at half opacity", [4]uint8{200, 100, 0, 64}, [4]uint8{0, 0, 255, 200},
+			blendOptions{Mode: "normal", Opacity: 0.5, AlphaMode: "source-over"}, [4]uint8{56, 28, 184, 139}},
+		{"source-over onto transparent", [4]uint8{200, 100, 0, 0}, [4]uint8{10, 20, 30, 90},
+			blendOptions{Mode: "multiply", Opacity: 1, AlphaMode: "source-over"}, [4]uint8{10, 20, 30, 90}},
+		// The other modes mix colors by the top alpha alone
+		{"keep-base", [4]uint8{200, 100, 0, 128}, [4]uint8{0, 0, 255, 64},
+			blendOptions{Mode: "normal", Opacity: 1, AlphaMode: "keep-base"}, [4]uint8{150, 75, 64, 128}},
+		{"max", [4]uint8{200, 100, 0, 64}, [4]uint8{0, 0, 255, 200},
+			blendOptions{Mode: "normal", Opacity: 1, AlphaMode: "max"}, [4]uint8{43, 22, 200, 200}},
+	} {
+		got := blendImages(tc.base[:], tc.top[:], 1, 1, tc.opts)
+		if [4]uint8(got) != tc.want {
+			t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
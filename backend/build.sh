@@ -1,7 +1,13 @@
 #!/bin/bash
 
-# Compile Go code (main.go) in the current directory to WebAssembly
-GOOS=js GOARCH=wasm go build -o ../frontend/public/main.wasm main.go
+# Stamp the commit hash and build date into the binary (reported by getBuildInfo)
+BUILD_HASH=$(git rev-parse --short HEAD 2>/dev/null || echo unknown)
+BUILD_DATE=$(date -u +%Y-%m-%dT%H:%M:%SZ)
+
+# Compile the Go package in the current directory to WebAssembly
+GOOS=js GOARCH=wasm go build \
+  -ldflags "-X main.buildHash=$BUILD_HASH -X main.buildDate=$BUILD_DATE" \
+  -o ../frontend/public/main.wasm .
 
 # Find the wasm_exec.js file
 WASM_EXEC_PATH=$(go env GOROOT)/misc/wasm/wasm_exec.js
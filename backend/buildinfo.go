@@ -0,0 +1,52 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import (
+	"runtime"
+	"runtime/debug"
+	"syscall/js"
+)
+
+// Build metadata, injected at link time by build.sh:
+//
+//	go build -ldflags "-X main.buildHash=$(git rev-parse --short HEAD) -X main.buildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+//
+// moduleVersion tracks the frontend package version and can be overridden the same way.
+var (
+	moduleVersion = "0.0.0"
+	buildHash     = ""
+	buildDate     = ""
+)
+
+// getBuildInfoWrapper exposes the build metadata to JavaScript.
+// It returns { version, goVersion, buildHash, buildDate }. When the hash was not injected
+// it falls back to the VCS revision the Go toolchain stamped into the binary, and then to
+// "unknown".
+func getBuildInfoWrapper(this js.Value, args []js.Value) interface{} {
+	hash, date := buildHash, buildDate
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range info.Settings {
+			switch {
+			case s.Key == "vcs.revision" && hash == "":
+				hash = s.Value
+			case s.Key == "vcs.time" && date == "":
+				date = s.Value
+			}
+		}
+	}
+	if hash == "" {
+		hash = "unknown"
+	}
+	if date == "" {
+		date = "unknown"
+	}
+
+	result := js.Global().Get("Object").New()
+	result.Set("version", moduleVersion)
+	result.Set("goVersion", runtime.Version())
+	result.Set("buildHash", hash)
+	result.Set("buildDate", date)
+	return result
+}
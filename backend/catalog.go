@@ -0,0 +1,336 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"syscall/js"
+)
+
+// paramSpec describes a single filter parameter: its type, valid range, and default.
+type paramSpec struct {
+	Name        string
+	Type        string // "number", "integer", "string", "boolean", "array" (of numbers), or "image"
+	Min, Max    float64
+	Default     interface{} // float64, string, bool, or []float64 matching Type; nil for "image"
+	Options     []string    // Allowed values for "string" parameters
+	Description string
+}
+
+// filterSpec describes a filter type accepted by applyFilter.
+type filterSpec struct {
+	Name        string
+	Description string
+	Params      []paramSpec
+}
+
+// edgeParams are the border-handling parameters shared by the convolution filters.
+var edgeParams = []paramSpec{
+	{Name: "edgeMode", Type: "string", Default: "clamp", Options: []string{"clamp", "reflect", "wrap", "constant"}, Description: "How samples beyond the border are taken: repeat the edge pixel, mirror, tile, or use fillColor"},
+	{Name: "fillColor", Type: "array", Default: []float64{0, 0, 0}, Description: "[r, g, b] used for samples beyond the border in constant mode"},
+}
+
+// filterCatalog is the single source of truth for the filters applyFilter supports and
+// the parameters each one accepts. applyFilter resolves every call's params against it
+// (validating ranges and filling in defaults), and listFilters returns it to JavaScript,
+// so the UI and the processing code cannot disagree.
+var filterCatalog = []filterSpec{
+	{Name: "blur", Description: "3x3 box blur", Params: edgeParams},
+	{Name: "sharpen", Description: "3x3 sharpening kernel", Params: edgeParams},
+	{Name: "edge", Description: "3x3 Laplacian edge detection", Params: edgeParams},
+	{
+		Name:        "gaussian",
+		Description: "Gaussian blur with a kernel sized to sigma",
+		Params: append([]paramSpec{
+			{Name: "sigma", Type: "number", Min: 0.1, Max: 50, Default: 1.0, Description: "Standard deviation in pixels; the kernel radius is ceil(3*sigma)"},
+		}, edgeParams...),
+	},
+	{
+		Name:        "custom",
+		Description: "Convolution with a caller-supplied odd-sized kernel",
+		Params: append([]paramSpec{
+			{Name: "kernel", Type: "array", Default: []float64{0, 0, 0, 0, 1, 0, 0, 0, 0}, Description: "size*size weights in row-major order (a Float64Array or plain array)"},
+			{Name: "size", Type: "integer", Min: 1, Max: 31, Default: 3.0, Description: "Kernel width and height; must be odd"},
+			{Name: "precise", Type: "boolean", Default: false, Description: "Accumulate in float64 instead of float32, for large kernels or weights that nearly cancel"},
+		}, edgeParams...),
+	},
+	{
+		Name:        "emboss",
+		Description: "3x3 emboss relief on mid-gray",
+		Params: append([]paramSpec{
+			{Name: "color", Type: "boolean", Default: false, Description: "Shade the original colors with the relief instead of embossing each channel"},
+		}, edgeParams...),
+	},
+	{
+		Name:        "defringe",
+		Description: "Desaturates purple/green chromatic-aberration fringes next to high-contrast edges",
+		Params: []paramSpec{
+			{Name: "threshold", Type: "number", Min: 0, Max: 1500, Default: 60.0, Description: "Sobel gradient magnitude that marks an edge"},
+			{Name: "amount", Type: "number", Min: 0, Max: 1, Default: 1.0, Description: "Desaturation strength"},
+			{Name: "radius", Type: "integer", Min: 0, Max: 16, Default: 2.0, Description: "Distance from an edge, in pixels, that counts as near"},
+		},
+	},
+	{
+		Name:        "nlmeans",
+		Description: "Non-local means denoising (slow on large images)",
+		Params: []paramSpec{
+			{Name: "patchSize", Type: "integer", Min: 1, Max: 15, Default: 3.0, Description: "Side length of the compared patches"},
+			{Name: "searchWindow", Type: "integer", Min: 1, Max: 41, Default: 11.0, Description: "Side length of the search window"},
+			{Name: "strength", Type: "number", Min: 0.01, Max: 255, Default: 10.0, Description: "Filtering strength h; higher smooths more"},
+		},
+	},
+	{
+		Name:        "protect-sharpen",
+		Description: "Edge-aware sharpening that leaves low-contrast (noisy, flat) areas alone",
+		Params: []paramSpec{
+			{Name: "amount", Type: "number", Min: 0, Max: 5, Default: 1.0, Description: "Sharpening strength at full-contrast edges"},
+			{Name: "threshold", Type: "number", Min: 0, Max: 255, Default: 20.0, Description: "Local luminance range below which no sharpening is applied"},
+		},
+	},
+	{
+		Name:        "fft-lowpass",
+		Description: "Ideal frequency-domain low-pass: removes frequencies beyond the cutoff radius",
+		Params: []paramSpec{
+			{Name: "radius", Type: "number", Min: 0, Max: 100000, Default: 30.0, Description: "Cutoff radius in frequency bins (cycles per image)"},
+		},
+	},
+	{
+		Name:        "fft-highpass",
+		Description: "Ideal frequency-domain high-pass: removes frequencies within the cutoff radius (output biased to mid-gray)",
+		Params: []paramSpec{
+			{Name: "radius", Type: "number", Min: 0, Max: 100000, Default: 10.0, Description: "Cutoff radius in frequency bins (cycles per image)"},
+		},
+	},
+	{
+		Name:        "gradient-direction",
+		Description: "Visualizes Sobel edge orientation as hue and edge strength as brightness",
+		Params: []paramSpec{
+			{Name: "scale", Type: "number", Min: 0, Max: 20, Default: 1.0, Description: "Brightness gain applied to the gradient magnitude"},
+		},
+	},
+	{
+		Name:        "spline-curve",
+		Description: "Tone curve through control points using a monotonic cubic spline",
+		Params: []paramSpec{
+			{Name: "points", Type: "array", Default: []float64{0, 0, 255, 255}, Description: "Flat [x0, y0, x1, y1, ...] control points in 0-255 with strictly increasing x"},
+		},
+	},
+	{
+		Name:        "tone",
+		Description: "Brightness, contrast, and gamma applied through a 256-entry lookup table",
+		Params: []paramSpec{
+			{Name: "brightness", Type: "number", Min: -255, Max: 255, Default: 0.0, Description: "Offset added to every channel"},
+			{Name: "contrast", Type: "number", Min: -100, Max: 100, Default: 0.0, Description: "Percent change in contrast around mid-gray"},
+			{Name: "gamma", Type: "number", Min: 0.1, Max: 10, Default: 1.0, Description: "Gamma; values above 1 brighten midtones"},
+		},
+	},
+	{
+		Name:        "duotone",
+		Description: "Maps luminance onto a gradient between a shadow color and a highlight color",
+		Params: []paramSpec{
+			{Name: "shadow", Type: "array", Default: []float64{20, 30, 80}, Description: "[r, g, b] color for black"},
+			{Name: "highlight", Type: "array", Default: []float64{255, 220, 150}, Description: "[r, g, b] color for white"},
+		},
+	},
+	{
+		Name:        "colorblind",
+		Description: "Simulates how the image appears with a color-vision deficiency (Machado et al. 2009)",
+		Params: []paramSpec{
+			{Name: "type", Type: "string", Default: "protanopia", Options: []string{"protanopia", "deuteranopia", "tritanopia"}, Description: "Deficiency to simulate"},
+		},
+	},
+	{
+		Name:        "clahe",
+		Description: "Contrast-limited adaptive histogram equalization of luminance",
+		Params: []paramSpec{
+			{Name: "tiles", Type: "integer", Min: 1, Max: 64, Default: 8.0, Description: "Number of tiles along each axis"},
+			{Name: "clipLimit", Type: "number", Min: 1, Max: 256, Default: 2.0, Description: "Histogram bin cap as a multiple of the average bin height; lower values limit contrast more"},
+		},
+	},
+	{
+		Name:        "glitch",
+		Description: "Digital-glitch stylization: RGB channel offsets, shifted row bands, and displaced blocks",
+		Params: []paramSpec{
+			{Name: "seed", Type: "integer", Min: 0, Max: 2147483647, Default: 1.0, Description: "Random seed; the same seed reproduces the same glitch"},
+			{Name: "intensity", Type: "number", Min: 0, Max: 1, Default: 0.5, Description: "Strength and frequency of the effects; 0 leaves the image unchanged"},
+		},
+	},
+	{
+		Name:        "displace",
+		Description: "Warps the image by a displacement map whose red/green channels encode x/y offsets",
+		Params: []paramSpec{
+			{Name: "map", Type: "image", Description: "imageData of the same size; 128 is no offset, 255 and 1 are +scale and -scale"},
+			{Name: "scale", Type: "number", Min: -1000, Max: 1000, Default: 10.0, Description: "Maximum displacement in pixels"},
+		},
+	},
+	{
+		Name:        "local-laplacian",
+		Description: "Edge-aware detail enhancement or smoothing with a local Laplacian pyramid (compute-heavy)",
+		Params: []paramSpec{
+			{Name: "amount", Type: "number", Min: 0.1, Max: 5, Default: 2.0, Description: "Detail gain; above 1 enhances local detail, below 1 smooths it, 1 leaves the image unchanged"},
+			{Name: "sigma", Type: "number", Min: 1, Max: 255, Default: 40.0, Description: "Luminance difference treated as detail; larger differences are edges and are preserved"},
+		},
+	},
+	{
+		Name:        "selective-color",
+		Description: "Adjusts the cyan, magenta, yellow, and black content of individual color ranges",
+		Params: []paramSpec{
+			{Name: "reds", Type: "array", Default: []float64{0, 0, 0, 0}, Description: "[cyan, magenta, yellow, black] shifts in percent (-100 to 100) for reds"},
+			{Name: "yellows", Type: "array", Default: []float64{0, 0, 0, 0}, Description: "[cyan, magenta, yellow, black] shifts in percent (-100 to 100) for yellows"},
+			{Name: "greens", Type: "array", Default: []float64{0, 0, 0, 0}, Description: "[cyan, magenta, yellow, black] shifts in percent (-100 to 100) for greens"},
+			{Name: "cyans", Type: "array", Default: []float64{0, 0, 0, 0}, Description: "[cyan, magenta, yellow, black] shifts in percent (-100 to 100) for cyans"},
+			{Name: "blues", Type: "array", Default: []float64{0, 0, 0, 0}, Description: "[cyan, magenta, yellow, black] shifts in percent (-100 to 100) for blues"},
+			{Name: "magentas", Type: "array", Default: []float64{0, 0, 0, 0}, Description: "[cyan, magenta, yellow, black] shifts in percent (-100 to 100) for magentas"},
+			{Name: "neutrals", Type: "array", Default: []float64{0, 0, 0, 0}, Description: "[cyan, magenta, yellow, black] shifts in percent (-100 to 100) for neutral grays"},
+			{Name: "method", Type: "string", Default: "relative", Options: []string{"relative", "absolute"}, Description: "Scale shifts by the existing ink amount (relative) or apply them as-is (absolute)"},
+		},
+	},
+	{
+		Name:        "skin-detect",
+		Description: "Finds skin-tone pixels with fixed YCbCr chroma thresholds",
+		Params: []paramSpec{
+			{Name: "output", Type: "string", Default: "overlay", Options: []string{"overlay", "mask"}, Description: "Dim and desaturate non-skin areas (overlay) or output a black-and-white mask"},
+		},
+	},
+	{Name: "sobel", Description: "Per-channel Sobel gradient magnitude edge detection"},
+	{
+		Name:        "median",
+		Description: "Median filter that removes salt-and-pepper noise while keeping edges",
+		Params: []paramSpec{
+			{Name: "size", Type: "integer", Min: 1, Max: 15, Default: 3.0, Description: "Window width and height; must be odd"},
+		},
+	},
+	{
+		Name:        "grayscale",
+		Description: "Converts to gray using luma weights",
+		Params: []paramSpec{
+			{Name: "weights", Type: "string", Default: "rec601", Options: []string{"rec601", "rec709"}, Description: "Rec.601 (0.299, 0.587, 0.114) or Rec.709 (0.2126, 0.7152, 0.0722) luma weights"},
+		},
+	},
+	{Name: "invert", Description: "Negative: maps each color channel to 255 minus its value"},
+	{Name: "sepia", Description: "Warm brown sepia tone via the standard sepia color matrix"},
+	{
+		Name:        "threshold",
+		Description: "Black-and-white binarization by luminance",
+		Params: []paramSpec{
+			{Name: "threshold", Type: "number", Min: 0, Max: 255, Default: 128.0, Description: "Pixels with luma above this become white, the rest black"},
+		},
+	},
+	{Name: "histeq", Description: "Histogram equalization of luminance, scaling RGB together to keep hues"},
+}
+
+// findFilterSpec looks up a filter in filterCatalog by name.
+func findFilterSpec(name string) (filterSpec, bool) {
+	for _, spec := range filterCatalog {
+		if spec.Name == name {
+			return spec, true
+		}
+	}
+	return filterSpec{}, false
+}
+
+// resolveParams validates the caller's params against the spec and returns a new set with
+// defaults filled in for every parameter the caller omitted. Keys the spec does not
+// declare are ignored.
+func (spec filterSpec) resolveParams(params filterParams) (filterParams, error) {
+	resolved := filterParams{}
+	for _, ps := range spec.Params {
+		raw, ok := params[ps.Name]
+		if !ok {
+			resolved[ps.Name] = ps.Default
+			continue
+		}
+
+		switch ps.Type {
+		case "number", "integer":
+			v, ok := raw.(float64)
+			if !ok || math.IsNaN(v) {
+				return nil, fmt.Errorf("Invalid parameter %q for filter '%s': expected a number", ps.Name, spec.Name)
+			}
+			if ps.Type == "integer" && v != math.Trunc(v) {
+				return nil, fmt.Errorf("Invalid parameter %q for filter '%s': expected an integer", ps.Name, spec.Name)
+			}
+			if v < ps.Min || v > ps.Max {
+				return nil, fmt.Errorf("Invalid parameter %q for filter '%s': %v is outside [%v, %v]", ps.Name, spec.Name, v, ps.Min, ps.Max)
+			}
+		case "string":
+			v, ok := raw.(string)
+			if !ok {
+				return nil, fmt.Errorf("Invalid parameter %q for filter '%s': expected a string", ps.Name, spec.Name)
+			}
+			if len(ps.Options) > 0 && !containsString(ps.Options, v) {
+				return nil, fmt.Errorf("Invalid parameter %q for filter '%s': '%s' is not one of %v", ps.Name, spec.Name, v, ps.Options)
+			}
+		case "boolean":
+			if _, ok := raw.(bool); !ok {
+				return nil, fmt.Errorf("Invalid parameter %q for filter '%s': expected a boolean", ps.Name, spec.Name)
+			}
+		case "array":
+			if _, ok := raw.([]float64); !ok {
+				return nil, fmt.Errorf("Invalid parameter %q for filter '%s': expected an array of numbers", ps.Name, spec.Name)
+			}
+		case "image":
+			if _, ok := raw.(paramImage); !ok {
+				return nil, fmt.Errorf("Invalid parameter %q for filter '%s': expected an imageData object", ps.Name, spec.Name)
+			}
+		}
+		resolved[ps.Name] = raw
+	}
+	return resolved, nil
+}
+
+// containsString reports whether s is one of values.
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// listFiltersWrapper exposes filterCatalog to JavaScript.
+// It returns an array of { name, description, params: [{ name, type, min, max, default,
+// options, description }] } describing every supported filter type.
+func listFiltersWrapper(this js.Value, args []js.Value) interface{} {
+	filters := js.Global().Get("Array").New()
+	for _, spec := range filterCatalog {
+		params := js.Global().Get("Array").New()
+		for _, ps := range spec.Params {
+			p := js.Global().Get("Object").New()
+			p.Set("name", ps.Name)
+			p.Set("type", ps.Type)
+			if ps.Type == "number" || ps.Type == "integer" {
+				p.Set("min", ps.Min)
+				p.Set("max", ps.Max)
+			}
+			if values, ok := ps.Default.([]float64); ok {
+				defaults := make([]interface{}, len(values))
+				for i, v := range values {
+					defaults[i] = v
+				}
+				p.Set("default", defaults)
+			} else {
+				p.Set("default", ps.Default)
+			}
+			if len(ps.Options) > 0 {
+				options := js.Global().Get("Array").New()
+				for _, o := range ps.Options {
+					options.Call("push", o)
+				}
+				p.Set("options", options)
+			}
+			p.Set("description", ps.Description)
+			params.Call("push", p)
+		}
+
+		f := js.Global().Get("Object").New()
+		f.Set("name", spec.Name)
+		f.Set("description", spec.Description)
+		f.Set("params", params)
+		filters.Call("push", f)
+	}
+	return filters
+}
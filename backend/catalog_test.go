@@ -0,0 +1,62 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestResolveParams(t *testing.T) {
+	spec := filterSpec{Name: "test", Params: []paramSpec{
+		{Name: "n", Type: "number", Min: -1, Max: 1, Default: 0.5},
+		{Name: "i", Type: "integer", Min: 1, Max: 9, Default: 3.0},
+		{Name: "s", Type: "string", Default: "a", Options: []string{"a", "b"}},
+		{Name: "b", Type: "boolean", Default: false},
+		{Name: "arr", Type: "array", Default: []float64{1, 2}},
+	}}
+
+	got, err := spec.resolveParams(filterParams{"i": 7.0, "s": "b", "unknown": "ignored"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.num("n") != 0.5 || got.int("i") != 7 || got.str("s") != "b" || got.boolean("b") || len(got.array("arr")) != 2 {
+		t.Errorf("resolved %v", got)
+	}
+	if _, ok := got["unknown"]; ok {
+		t.Error("an undeclared key was kept")
+	}
+
+	for _, bad := range []filterParams{
+		{"n": 1.5},
+		{"n": math.NaN()},
+		{"n": "0.5"},
+		{"i": 2.5},
+		{"i": 0.0},
+		{"s": "c"},
+		{"s": 1.0},
+		{"b": 1.0},
+		{"arr": 1.0},
+	} {
+		if _, err := spec.resolveParams(bad); err == nil {
+			t.Errorf("params %v were accepted", bad)
+		}
+	}
+}
+
+// TestCatalogDefaultsResolve checks that every default in filterCatalog passes its own
+// validation, so omitting a parameter never yields a value the caller could not pass.
+func TestCatalogDefaultsResolve(t *testing.T) {
+	for _, spec := range filterCatalog {
+		defaults := filterParams{}
+		for _, ps := range spec.Params {
+			if ps.Default != nil {
+				defaults[ps.Name] = ps.Default
+			}
+		}
+		if _, err := spec.resolveParams(defaults); err != nil {
+			t.Errorf("%s: %v", spec.Name, err)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"strings"
+	"syscall/js"
+	"time"
+)
+
+const pngDataURLPrefix = "data:image/png;base64,"
+
+// toDataURLWrapper wraps the toDataURL logic for syscall/js interaction.
+// It expects imageData { width, height, data: Uint8ClampedArray }.
+// It returns a "data:image/png;base64,..." string or an error object.
+func toDataURLWrapper(this js.Value, args []js.Value) interface{} {
+	startTime := time.Now()
+	logDebug("toDataURLWrapper called")
+
+	if len(args) < 1 {
+		return createError("Invalid number of arguments for toDataURL: expected 1 (imageData)")
+	}
+
+	srcData, width, height, err := readImageData(args[0])
+	if err != nil {
+		return createError(err.Error())
+	}
+
+	dataURL, err := toDataURL(srcData, width, height)
+	if err != nil {
+		return createError(err.Error())
+	}
+
+	logInfo("toDataURLWrapper completed in %v", time.Since(startTime))
+	return dataURL
+}
+
+// fromDataURLWrapper wraps the fromDataURL logic for syscall/js interaction.
+// It expects a PNG data URL string.
+// It returns { width, height, data: Uint8ClampedArray } or an error object.
+func fromDataURLWrapper(this js.Value, args []js.Value) interface{} {
+	startTime := time.Now()
+	logDebug("fromDataURLWrapper called")
+
+	if len(args) < 1 || args[0].Type() != js.TypeString {
+		return createError("Invalid arguments for fromDataURL: expected 1 (dataURL string)")
+	}
+
+	data, width, height, err := fromDataURL(args[0].String())
+	if err != nil {
+		return createError(err.Error())
+	}
+
+	logInfo("fromDataURLWrapper completed in %v", time.Since(startTime))
+	return imageDataToJS(data, width, height)
+}
+
+// toDataURL encodes RGBA pixel data as a base64 PNG data URL (internal logic).
+// Canvas ImageData uses straight (non-premultiplied) alpha, which maps directly onto NRGBA.
+func toDataURL(srcData []uint8, width, height int) (string, error) {
+	img := &image.NRGBA{
+		Pix:    srcData,
+		Stride: width * 4,
+		Rect:   image.Rect(0, 0, width, height),
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return "", fmt.Errorf("Failed to encode PNG: %v", err)
+	}
+	return pngDataURLPrefix + base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// fromDataURL decodes a base64 PNG data URL into straight-alpha RGBA pixel data
+// (internal logic). Returns the pixels and their dimensions.
+func fromDataURL(dataURL string) ([]uint8, int, int, error) {
+	if !strings.HasPrefix(dataURL, pngDataURLPrefix) {
+		return nil, 0, 0, errors.New("Invalid data URL: expected a \"data:image/png;base64,\" prefix")
+	}
+
+	encoded, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(dataURL, pngDataURLPrefix))
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("Invalid data URL: malformed base64 payload: %v", err)
+	}
+	decoded, err := png.Decode(bytes.NewReader(encoded))
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("Invalid data URL: failed to decode PNG: %v", err)
+	}
+
+	// Normalize whatever PNG color model was stored into NRGBA
+	bounds := decoded.Bounds()
+	img := image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(img, img.Bounds(), decoded, bounds.Min, draw.Src)
+	return img.Pix, bounds.Dx(), bounds.Dy(), nil
+}
+
+// packedChannelShifts gives, for each supported packed pixel format, the bit offset of the
+// R, G, B, and A channels within the uint32 value. Format names list the channels from the
+// most significant byte to the least, so "argb" is 0xAARRGGBB.
+var packedChannelShifts = map[string][4]uint{
+	"rgba": {24, 16, 8, 0},
+	"argb": {16, 8, 0, 24},
+	"abgr": {0, 8, 16, 24},
+	"bgra": {8, 16, 24, 0},
+}
+
+// readPackedPixels copies a Uint32Array of packed pixels out of JavaScript and unpacks it to
+// RGBA bytes. The optional imageData.pixelFormat names the channel layout of each uint32
+// value (see packedChannelShifts) and imageData.byteOrder ("little" or "big") says how those
+// values are stored in memory. The defaults, "abgr" and "little", match a Uint32Array view
+// over canvas ImageData on the little-endian hosts browsers run on.
+func readPackedPixels(imageDataJS, dataVal js.Value) ([]uint8, error) {
+	format := "abgr"
+	if v := imageDataJS.Get("pixelFormat"); v.Type() == js.TypeString {
+		format = v.String()
+	}
+	byteOrderName := "little"
+	if v := imageDataJS.Get("byteOrder"); v.Type() == js.TypeString {
+		byteOrderName = v.String()
+	}
+
+	var order binary.ByteOrder
+	switch byteOrderName {
+	case "little":
+		order = binary.LittleEndian
+	case "big":
+		order = binary.BigEndian
+	default:
+		return nil, fmt.Errorf("Invalid byteOrder '%s': expected 'little' or 'big'", byteOrderName)
+	}
+
+	// CopyBytesToGo only accepts byte arrays, so view the same memory as bytes
+	raw := make([]byte, dataVal.Get("byteLength").Int())
+	bytesView := js.Global().Get("Uint8Array").New(dataVal.Get("buffer"), dataVal.Get("byteOffset"), len(raw))
+	if copied := js.CopyBytesToGo(raw, bytesView); copied != len(raw) {
+		return nil, fmt.Errorf("Failed to copy packed image data from JavaScript: copied %d, expected %d", copied, len(raw))
+	}
+	return unpackPixels(raw, order, format)
+}
+
+// unpackPixels decodes packed uint32 pixels stored in raw with the given byte order and
+// channel layout into interleaved RGBA bytes.
+func unpackPixels(raw []byte, order binary.ByteOrder, format string) ([]uint8, error) {
+	shifts, ok := packedChannelShifts[format]
+	if !ok {
+		return nil, fmt.Errorf("Invalid pixelFormat '%s': expected one of rgba, argb, abgr, bgra", format)
+	}
+
+	data := make([]uint8, len(raw))
+	for i := 0; i+4 <= len(raw); i += 4 {
+		pixel := order.Uint32(raw[i:])
+		for c := 0; c < 4; c++ {
+			data[i+c] = uint8(pixel >> shifts[c])
+		}
+	}
+	return data, nil
+}
@@ -0,0 +1,111 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"syscall/js"
+	"testing"
+)
+
+func TestDataURLRoundTrip(t *testing.T) {
+	width, height := 9, 7
+	src := randomImage(width, height, 1)
+	for i := 3; i < len(src); i += 16 {
+		src[i] = uint8(i) // Some translucent pixels, which must survive unpremultiplied
+	}
+
+	url, err := toDataURL(src, width, height)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(url, "data:image/png;base64,") {
+		t.Fatalf("data URL starts %q", url[:min(len(url), 30)])
+	}
+	got, w, h, err := fromDataURL(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w != width || h != height || !bytes.Equal(got, src) {
+		t.Errorf("round trip gave a different %dx%d image", w, h)
+	}
+
+	for _, bad := range []string{
+		"",
+		"data:image/jpeg;base64,AAAA",
+		"data:image/png;base64,not base64!",
+		"data:image/png;base64,AAAAAAAA",
+	} {
+		if _, _, _, err := fromDataURL(bad); err == nil {
+			t.Errorf("fromDataURL(%q) did not return an error", bad)
+		}
+	}
+}
+
+func TestUnpackPixels(t *testing.T) {
+	want := []uint8{0x11, 0x22, 0x33, 0x44} // R, G, B, A
+	for _, tc := range []struct {
+		format string
+		order  binary.ByteOrder
+		raw    []byte
+	}{
+		{"rgba", binary.BigEndian, []byte{0x11, 0x22, 0x33, 0x44}},
+		{"rgba", binary.LittleEndian, []byte{0x44, 0x33, 0x22, 0x11}},
+		{"argb", binary.BigEndian, []byte{0x44, 0x11, 0x22, 0x33}},
+		{"argb", binary.LittleEndian, []byte{0x33, 0x22, 0x11, 0x44}},
+		{"abgr", binary.BigEndian, []byte{0x44, 0x33, 0x22, 0x11}},
+		{"abgr", binary.LittleEndian, []byte{0x11, 0x22, 0x33, 0x44}},
+		{"bgra", binary.BigEndian, []byte{0x33, 0x22, 0x11, 0x44}},
+		{"bgra", binary.LittleEndian, []byte{0x44, 0x11, 0x22, 0x33}},
+	} {
+		got, err := unpackPixels(tc.raw, tc.order, tc.format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("%s %v: unpacked % x, want % x", tc.format, tc.order, got, want)
+		}
+	}
+
+	if _, err := unpackPixels(make([]byte, 4), binary.LittleEndian, "rgb"); err == nil {
+		t.Error("unknown format \"rgb\" was accepted")
+	}
+}
+
+func TestBytesToJSOwnsTransferableBuffer(t *testing.T) {
+	data := randomImage(8, 8, 3)
+	result := bytesToJS(data)
+	if result.Get("byteOffset").Int() != 0 || result.Get("buffer").Get("byteLength").Int() != len(data) {
+		t.Fatal("result does not own a dedicated ArrayBuffer")
+	}
+	if got := jsBytes(result); !bytes.Equal(got, data) {
+		t.Fatal("result does not hold the data")
+	}
+
+	// Transferring detaches the buffer here and hands the same bytes to the receiver
+	buffer := result.Get("buffer")
+	transfer := js.Global().Get("Object").New()
+	transfer.Set("transfer", []interface{}{buffer})
+	received := js.Global().Call("structuredClone", buffer, transfer)
+	if buffer.Get("byteLength").Int() != 0 {
+		t.Error("the buffer was not detached by the transfer")
+	}
+	got := make([]uint8, len(data))
+	js.CopyBytesToGo(got, js.Global().Get("Uint8Array").New(received))
+	if !bytes.Equal(got, data) {
+		t.Error("the transferred buffer holds different bytes")
+	}
+}
+
+// BenchmarkBytesToJS measures the one copy every result pays to leave the Go heap, for a
+// 1024x1024 image.
+func BenchmarkBytesToJS(b *testing.B) {
+	data := randomImage(1024, 1024, 1)
+	b.SetBytes(int64(len(data)))
+	for i := 0; i < b.N; i++ {
+		bytesToJS(data)
+	}
+}
@@ -0,0 +1,731 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"syscall/js"
+	"time"
+)
+
+// rgbToYCbCr converts an RGB triple to full-range (JPEG/JFIF) YCbCr.
+func rgbToYCbCr(r, g, b float64) (float64, float64, float64) {
+	y := 0.299*r + 0.587*g + 0.114*b
+	cb := 128 - 0.168736*r - 0.331264*g + 0.5*b
+	cr := 128 + 0.5*r - 0.418688*g - 0.081312*b
+	return y, cb, cr
+}
+
+// yCbCrToRGB converts full-range YCbCr back to RGB. Results are not clamped.
+func yCbCrToRGB(y, cb, cr float64) (float64, float64, float64) {
+	r := y + 1.402*(cr-128)
+	g := y - 0.344136*(cb-128) - 0.714136*(cr-128)
+	b := y + 1.772*(cb-128)
+	return r, g, b
+}
+
+// applyFilterLumaWrapper wraps the applyFilterLuma logic for syscall/js interaction.
+// It expects imageData { width, height, data: Uint8ClampedArray }, filterType string, and an
+// optional params object, exactly like applyFilter.
+// It returns the processed Uint8ClampedArray or an error object.
+func applyFilterLumaWrapper(this js.Value, args []js.Value) interface{} {
+	startTime := time.Now()
+	logDebug("applyFilterLumaWrapper called")
+
+	if len(args) < 2 {
+		return createError("Invalid number of arguments for applyFilterLuma: expected 2 (imageData, filterType)")
+	}
+
+	srcData, width, height, err := readImageData(args[0])
+	if err != nil {
+		return createError(err.Error())
+	}
+	filterType := args[1].String()
+	var paramsJS js.Value
+	if len(args) > 2 {
+		paramsJS = args[2]
+	}
+	params, err := readFilterParams(paramsJS)
+	if err != nil {
+		return createError(err.Error())
+	}
+
+	resultData, err := applyFilterLuma(srcData, width, height, filterType, params)
+	if err != nil {
+		return createError(err.Error())
+	}
+
+	logInfo("applyFilterLumaWrapper completed in %v", time.Since(startTime))
+	return bytesToJS(resultData)
+}
+
+// applyFilterLuma applies a filter to the luminance channel only (internal logic).
+// The image is converted to YCbCr, Y is written into a grayscale image and run through
+// applyFilter, and the filtered Y is recombined with the untouched Cb/Cr. Sharpening or
+// blurring this way avoids the color shifts caused by filtering R, G, B independently.
+func applyFilterLuma(srcData []uint8, width, height int, filterType string, params filterParams) ([]uint8, error) {
+	numPixels := width * height
+	yPlane := make([]float64, numPixels)
+	cbPlane := make([]float64, numPixels)
+	crPlane := make([]float64, numPixels)
+	lumaImage := make([]uint8, len(srcData))
+
+	parallelRows(height, func(startY, endY int) {
+		for i := startY * width; i < endY*width; i++ {
+			idx := i * 4
+			y, cb, cr := rgbToYCbCr(float64(srcData[idx]), float64(srcData[idx+1]), float64(srcData[idx+2]))
+			yPlane[i], cbPlane[i], crPlane[i] = y, cb, cr
+			v := uint8(clampFloat64(y+0.5, 0, 255))
+			lumaImage[idx], lumaImage[idx+1], lumaImage[idx+2] = v, v, v
+			lumaImage[idx+3] = srcData[idx+3]
+		}
+	})
+
+	filtered, err := applyFilter(lumaImage, width, height, filterType, params)
+	if err != nil {
+		return nil, err
+	}
+
+	resultData := make([]uint8, len(srcData))
+	parallelRows(height, func(startY, endY int) {
+		for i := startY * width; i < endY*width; i++ {
+			idx := i * 4
+			// Apply the filter's change to the rounded Y on top of the exact Y, so that
+			// pixels the filter leaves alone round-trip unchanged
+			y := yPlane[i] + float64(filtered[idx]) - float64(lumaImage[idx])
+			r, g, b := yCbCrToRGB(y, cbPlane[i], crPlane[i])
+			resultData[idx] = uint8(clampFloat64(r+0.5, 0, 255))
+			resultData[idx+1] = uint8(clampFloat64(g+0.5, 0, 255))
+			resultData[idx+2] = uint8(clampFloat64(b+0.5, 0, 255))
+			resultData[idx+3] = srcData[idx+3]
+		}
+	})
+
+	return resultData, nil
+}
+
+// hsvToRGB converts hue (degrees), saturation and value (0-1) to RGB in [0, 255].
+func hsvToRGB(h, s, v float64) (float64, float64, float64) {
+	h = math.Mod(h, 360)
+	if h < 0 {
+		h += 360
+	}
+	c := v * s
+	r, g, b := hueChromaToRGB(h, c)
+	m := v - c
+	return (r + m) * 255, (g + m) * 255, (b + m) * 255
+}
+
+// hueChromaToRGB returns the RGB (0-1) of hue h (degrees, in [0, 360)) and chroma c before
+// the lightness offset that hsvToRGB and hslToRGB each add.
+func hueChromaToRGB(h, c float64) (float64, float64, float64) {
+	x := c * (1 - math.Abs(math.Mod(h/60, 2)-1))
+	var r, g, b float64
+	switch {
+	case h < 60:
+		r, g, b = c, x, 0
+	case h < 120:
+		r, g, b = x, c, 0
+	case h < 180:
+		r, g, b = 0, c, x
+	case h < 240:
+		r, g, b = 0, x, c
+	case h < 300:
+		r, g, b = x, 0, c
+	default:
+		r, g, b = c, 0, x
+	}
+	return r, g, b
+}
+
+// rgbToHSL converts RGB in [0, 255] to hue (degrees in [0, 360)), saturation and
+// lightness (0-1). Grays, whose hue is undefined, get hue 0 and saturation 0.
+func rgbToHSL(r, g, b float64) (float64, float64, float64) {
+	r, g, b = r/255, g/255, b/255
+	hi, lo := math.Max(r, math.Max(g, b)), math.Min(r, math.Min(g, b))
+	l := (hi + lo) / 2
+	c := hi - lo
+	if c == 0 {
+		return 0, 0, l
+	}
+	s := c / (1 - math.Abs(2*l-1))
+
+	var h float64
+	switch hi {
+	case r:
+		h = math.Mod((g-b)/c, 6)
+	case g:
+		h = (b-r)/c + 2
+	default:
+		h = (r-g)/c + 4
+	}
+	h *= 60
+	if h < 0 {
+		h += 360
+	}
+	return h, s, l
+}
+
+// hslToRGB converts hue (degrees), saturation and lightness (0-1) to RGB in [0, 255].
+func hslToRGB(h, s, l float64) (float64, float64, float64) {
+	h = math.Mod(h, 360)
+	if h < 0 {
+		h += 360
+	}
+	c := (1 - math.Abs(2*l-1)) * s
+	r, g, b := hueChromaToRGB(h, c)
+	m := l - c/2
+	return (r + m) * 255, (g + m) * 255, (b + m) * 255
+}
+
+// applyChannelLUT maps each RGB value through a 256-entry lookup table, leaving alpha intact.
+func applyChannelLUT(srcData []uint8, width, height int, lut *[256]uint8) []uint8 {
+	resultData := make([]uint8, len(srcData))
+	parallelRows(height, func(startY, endY int) {
+		for i := startY * width * 4; i < endY*width*4; i += 4 {
+			resultData[i] = lut[srcData[i]]
+			resultData[i+1] = lut[srcData[i+1]]
+			resultData[i+2] = lut[srcData[i+2]]
+			resultData[i+3] = srcData[i+3]
+		}
+	})
+	return resultData
+}
+
+// invertLUT maps each channel value v to 255 - v (the "invert" filter).
+var invertLUT = func() (lut [256]uint8) {
+	for v := range lut {
+		lut[v] = uint8(255 - v)
+	}
+	return lut
+}()
+
+// monotoneCubicLUT fits a Fritsch-Carlson monotone cubic spline through the control points
+// (xs strictly increasing) and samples it at 0..255. The tangents are limited so that the
+// curve never overshoots between points, which keeps tone curves free of kinks and
+// reversals. Inputs outside the first/last control point hold the end values.
+func monotoneCubicLUT(xs, ys []float64) [256]uint8 {
+	n := len(xs)
+	deltas := make([]float64, n-1)
+	for k := 0; k < n-1; k++ {
+		deltas[k] = (ys[k+1] - ys[k]) / (xs[k+1] - xs[k])
+	}
+
+	// Initial tangents: secant average, zero at local extrema
+	tangents := make([]float64, n)
+	tangents[0] = deltas[0]
+	tangents[n-1] = deltas[n-2]
+	for k := 1; k < n-1; k++ {
+		if deltas[k-1]*deltas[k] > 0 {
+			tangents[k] = (deltas[k-1] + deltas[k]) / 2
+		}
+	}
+
+	// Fritsch-Carlson limiting to preserve monotonicity
+	for k := 0; k < n-1; k++ {
+		if deltas[k] == 0 {
+			tangents[k], tangents[k+1] = 0, 0
+			continue
+		}
+		a := tangents[k] / deltas[k]
+		b := tangents[k+1] / deltas[k]
+		if s := a*a + b*b; s > 9 {
+			t := 3 / math.Sqrt(s)
+			tangents[k] = t * a * deltas[k]
+			tangents[k+1] = t * b * deltas[k]
+		}
+	}
+
+	var lut [256]uint8
+	k := 0
+	for i := 0; i < 256; i++ {
+		x := float64(i)
+		var y float64
+		switch {
+		case x <= xs[0]:
+			y = ys[0]
+		case x >= xs[n-1]:
+			y = ys[n-1]
+		default:
+			for x > xs[k+1] {
+				k++
+			}
+			// Cubic Hermite basis on segment k
+			h := xs[k+1] - xs[k]
+			t := (x - xs[k]) / h
+			t2, t3 := t*t, t*t*t
+			y = (2*t3-3*t2+1)*ys[k] + (t3-2*t2+t)*h*tangents[k] +
+				(-2*t3+3*t2)*ys[k+1] + (t3-t2)*h*tangents[k+1]
+		}
+		lut[i] = uint8(clampFloat64(y+0.5, 0, 255))
+	}
+	return lut
+}
+
+// applySplineCurve applies a smooth tone curve (internal logic for "spline-curve").
+// The control points are validated (at least two, within 0-255, x strictly increasing),
+// fitted with monotoneCubicLUT, and the resulting table is applied to R, G, and B.
+func applySplineCurve(srcData []uint8, width, height int, params filterParams) ([]uint8, error) {
+	points := params.array("points")
+	if len(points) < 4 || len(points)%2 != 0 {
+		return nil, errors.New("Invalid parameter \"points\" for filter 'spline-curve': expected at least two [x, y] pairs")
+	}
+	xs := make([]float64, len(points)/2)
+	ys := make([]float64, len(points)/2)
+	for i := range xs {
+		xs[i], ys[i] = points[2*i], points[2*i+1]
+		if xs[i] < 0 || xs[i] > 255 || ys[i] < 0 || ys[i] > 255 {
+			return nil, fmt.Errorf("Invalid parameter \"points\" for filter 'spline-curve': point %d is outside 0-255", i)
+		}
+		if i > 0 && xs[i] <= xs[i-1] {
+			return nil, fmt.Errorf("Invalid parameter \"points\" for filter 'spline-curve': x values must be strictly increasing (point %d)", i)
+		}
+	}
+
+	logDebug("Applying spline curve through %d points...", len(xs))
+	lut := monotoneCubicLUT(xs, ys)
+	return applyChannelLUT(srcData, width, height, &lut), nil
+}
+
+// colorParam reads an [r, g, b] array parameter with components in 0-255.
+func colorParam(params filterParams, filterType, name string) ([3]float64, error) {
+	var color [3]float64
+	values := params.array(name)
+	if len(values) != 3 {
+		return color, fmt.Errorf("Invalid parameter %q for filter '%s': expected an [r, g, b] array", name, filterType)
+	}
+	for i, v := range values {
+		if v < 0 || v > 255 {
+			return color, fmt.Errorf("Invalid parameter %q for filter '%s': components must be between 0 and 255", name, filterType)
+		}
+		color[i] = v
+	}
+	return color, nil
+}
+
+// applyDuotone recolors the image along a two-color gradient (internal logic for
+// "duotone"). A 256-entry color table interpolating linearly from `shadow` (luminance 0)
+// to `highlight` (luminance 255) is built once, and each pixel is looked up by its rounded
+// luminance. Alpha passes through.
+func applyDuotone(srcData []uint8, width, height int, params filterParams) ([]uint8, error) {
+	shadow, err := colorParam(params, "duotone", "shadow")
+	if err != nil {
+		return nil, err
+	}
+	highlight, err := colorParam(params, "duotone", "highlight")
+	if err != nil {
+		return nil, err
+	}
+
+	var lut [256][3]uint8
+	for i := range lut {
+		t := float64(i) / 255
+		for c := 0; c < 3; c++ {
+			lut[i][c] = uint8(shadow[c] + (highlight[c]-shadow[c])*t + 0.5)
+		}
+	}
+
+	resultData := make([]uint8, len(srcData))
+	parallelRows(height, func(startY, endY int) {
+		for i := startY * width * 4; i < endY*width*4; i += 4 {
+			l := int(luminance(srcData[i], srcData[i+1], srcData[i+2]) + 0.5)
+			color := lut[clamp(l, 0, 255)]
+			resultData[i], resultData[i+1], resultData[i+2] = color[0], color[1], color[2]
+			resultData[i+3] = srcData[i+3]
+		}
+	})
+	return resultData, nil
+}
+
+// colorBlindMatrices are the full-severity simulation matrices of Machado, Oliveira and
+// Fernandes (2009), applied to linear RGB.
+var colorBlindMatrices = map[string][9]float64{
+	"protanopia": {
+		0.152286, 1.052583, -0.204868,
+		0.114503, 0.786281, 0.099216,
+		-0.003882, -0.048116, 1.051998,
+	},
+	"deuteranopia": {
+		0.367322, 0.860646, -0.227968,
+		0.280085, 0.672501, 0.047413,
+		-0.011820, 0.042940, 0.968881,
+	},
+	"tritanopia": {
+		1.255528, -0.076749, -0.178779,
+		-0.078411, 0.930809, 0.147602,
+		0.004733, 0.691367, 0.303900,
+	},
+}
+
+// grayscaleMatrices write a weighted sum of R, G and B (the luma of the named standard)
+// to all three channels. They are applied to the gamma-encoded values, as luma is defined.
+var grayscaleMatrices = map[string][9]float64{
+	"rec601": {
+		0.299, 0.587, 0.114,
+		0.299, 0.587, 0.114,
+		0.299, 0.587, 0.114,
+	},
+	"rec709": {
+		0.2126, 0.7152, 0.0722,
+		0.2126, 0.7152, 0.0722,
+		0.2126, 0.7152, 0.0722,
+	},
+}
+
+// sepiaMatrix is the common sepia-tone matrix, applied to the 0-255 values.
+var sepiaMatrix = [9]float64{
+	0.393, 0.769, 0.189,
+	0.349, 0.686, 0.168,
+	0.272, 0.534, 0.131,
+}
+
+// applyThreshold binarizes the image (internal logic for "threshold"): pixels whose Rec.601
+// luma is above `threshold` become white and the rest black. Alpha passes through.
+func applyThreshold(srcData []uint8, width, height int, params filterParams) ([]uint8, error) {
+	threshold := params.num("threshold")
+	logDebug("Applying threshold at %.1f...", threshold)
+	resultData := make([]uint8, len(srcData))
+	parallelRows(height, func(startY, endY int) {
+		for i := startY * width * 4; i < endY*width*4; i += 4 {
+			var v uint8
+			if luminance(srcData[i], srcData[i+1], srcData[i+2]) > threshold {
+				v = 255
+			}
+			resultData[i], resultData[i+1], resultData[i+2] = v, v, v
+			resultData[i+3] = srcData[i+3]
+		}
+	})
+	return resultData, nil
+}
+
+// applyHistEq equalizes the luminance histogram (internal logic for "histeq"). Each rounded
+// luma level l is remapped through the cumulative histogram to
+//
+//	l' = 255 * (cdf(l) - cdf(lmin)) / (pixels - cdf(lmin))
+//
+// so the darkest level present becomes 0, the brightest 255, and the levels in between are
+// spread by how many pixels use them. R, G, and B are all multiplied by l'/l, which changes
+// brightness without shifting hue; channels pushed past 255 are clamped. A single-level
+// image has nothing to spread and is returned unchanged. Alpha passes through.
+func applyHistEq(srcData []uint8, width, height int, params filterParams) ([]uint8, error) {
+	logDebug("Applying histogram equalization...")
+	hist := channelHistograms(srcData, width, height)[4]
+	pixels := width * height
+
+	cdfMin := 0
+	for _, n := range hist {
+		if n > 0 {
+			cdfMin = n
+			break
+		}
+	}
+	if pixels == cdfMin {
+		logDebug("Histogram equalization skipped: single luminance level")
+		resultData := make([]uint8, len(srcData))
+		copy(resultData, srcData)
+		return resultData, nil
+	}
+
+	// scale[l] is the factor that takes luma level l to its equalized level
+	var scale [256]float64
+	cdf := 0
+	for l, n := range hist {
+		cdf += n
+		if l > 0 {
+			scale[l] = 255 * float64(cdf-cdfMin) / float64(pixels-cdfMin) / float64(l)
+		}
+	}
+
+	resultData := make([]uint8, len(srcData))
+	parallelRows(height, func(startY, endY int) {
+		for i := startY * width * 4; i < endY*width*4; i += 4 {
+			l := clamp(int(luminance(srcData[i], srcData[i+1], srcData[i+2])+0.5), 0, 255)
+			for c := 0; c < 3; c++ {
+				resultData[i+c] = uint8(clampFloat64(float64(srcData[i+c])*scale[l]+0.5, 0, 255))
+			}
+			resultData[i+3] = srcData[i+3]
+		}
+	})
+	return resultData, nil
+}
+
+// srgbToLinear decodes an 8-bit sRGB value to linear light in [0, 1].
+func srgbToLinear(v uint8) float64 {
+	c := float64(v) / 255
+	if c <= 0.04045 {
+		return c / 12.92
+	}
+	return math.Pow((c+0.055)/1.055, 2.4)
+}
+
+// linearToSRGB encodes linear light in [0, 1] as an sRGB value in [0, 255].
+func linearToSRGB(c float64) float64 {
+	c = clampFloat64(c, 0, 1)
+	if c <= 0.0031308 {
+		return c * 12.92 * 255
+	}
+	return (1.055*math.Pow(c, 1/2.4) - 0.055) * 255
+}
+
+// applyColorMatrix multiplies every pixel's RGB by a row-major 3x3 matrix, leaving alpha
+// intact. With linear set, the matrix is applied in linear light (sRGB is decoded first and
+// re-encoded after), otherwise directly to the 0-255 values.
+func applyColorMatrix(srcData []uint8, width, height int, m [9]float64, linear bool) []uint8 {
+	var decode [256]float64
+	for i := range decode {
+		if linear {
+			decode[i] = srgbToLinear(uint8(i))
+		} else {
+			decode[i] = float64(i)
+		}
+	}
+	encode := func(v float64) uint8 {
+		if linear {
+			v = linearToSRGB(v)
+		}
+		return uint8(clampFloat64(v+0.5, 0, 255))
+	}
+
+	resultData := make([]uint8, len(srcData))
+	parallelRows(height, func(startY, endY int) {
+		for i := startY * width * 4; i < endY*width*4; i += 4 {
+			r, g, b := decode[srcData[i]], decode[srcData[i+1]], decode[srcData[i+2]]
+			resultData[i] = encode(m[0]*r + m[1]*g + m[2]*b)
+			resultData[i+1] = encode(m[3]*r + m[4]*g + m[5]*b)
+			resultData[i+2] = encode(m[6]*r + m[7]*g + m[8]*b)
+			resultData[i+3] = srcData[i+3]
+		}
+	})
+	return resultData
+}
+
+// applyCLAHE performs contrast-limited adaptive histogram equalization on the luminance
+// (internal logic for "clahe"). The image is divided into tiles x tiles regions and each
+// region gets its own equalization curve, built from a histogram whose bins are capped at
+// clipLimit times the average bin height (the clipped excess is spread evenly over all bins)
+// so noise in flat regions is not blown up. Every pixel's new luminance is bilinearly
+// interpolated between the curves of the four nearest tile centers, which removes the
+// block edges plain per-tile equalization produces. Chroma is kept, so colors survive.
+func applyCLAHE(srcData []uint8, width, height int, params filterParams) ([]uint8, error) {
+	tilesX := min(params.int("tiles"), width)
+	tilesY := min(params.int("tiles"), height)
+	clipLimit := params.num("clipLimit")
+	logDebug("Applying CLAHE with %dx%d tiles, clip limit %.2f...", tilesX, tilesY, clipLimit)
+
+	numPixels := width * height
+	yPlane := make([]float64, numPixels)
+	cbPlane := make([]float64, numPixels)
+	crPlane := make([]float64, numPixels)
+	bins := make([]uint8, numPixels)
+	parallelRows(height, func(startY, endY int) {
+		for i := startY * width; i < endY*width; i++ {
+			idx := i * 4
+			y, cb, cr := rgbToYCbCr(float64(srcData[idx]), float64(srcData[idx+1]), float64(srcData[idx+2]))
+			yPlane[i], cbPlane[i], crPlane[i] = y, cb, cr
+			bins[i] = uint8(clampFloat64(y+0.5, 0, 255))
+		}
+	})
+
+	// Tile t along an axis of length n covers [t*n/tiles, (t+1)*n/tiles)
+	tileStart := func(t, n, tiles int) int { return t * n / tiles }
+
+	mappings := make([][256]float64, tilesX*tilesY)
+	parallelRows(tilesY, func(startTY, endTY int) {
+		for ty := startTY; ty < endTY; ty++ {
+			y0, y1 := tileStart(ty, height, tilesY), tileStart(ty+1, height, tilesY)
+			for tx := 0; tx < tilesX; tx++ {
+				x0, x1 := tileStart(tx, width, tilesX), tileStart(tx+1, width, tilesX)
+				var hist [256]int
+				for y := y0; y < y1; y++ {
+					for x := x0; x < x1; x++ {
+						hist[bins[y*width+x]]++
+					}
+				}
+
+				count := (x1 - x0) * (y1 - y0)
+				limit := max(1, int(clipLimit*float64(count)/256))
+				excess := 0
+				for v := range hist {
+					if hist[v] > limit {
+						excess += hist[v] - limit
+						hist[v] = limit
+					}
+				}
+				for v := range hist {
+					hist[v] += excess / 256
+				}
+				// Spread the remainder evenly across the range rather than at the low end
+				residual := excess % 256
+				for v, step := 0, max(256/max(residual, 1), 1); v < 256 && residual > 0; v += step {
+					hist[v]++
+					residual--
+				}
+
+				mapping := &mappings[ty*tilesX+tx]
+				cdf := 0
+				for v := range hist {
+					cdf += hist[v]
+					mapping[v] = float64(cdf) * 255 / float64(count)
+				}
+			}
+		}
+	})
+
+	// nearestTiles returns the two tile indices around pixel coordinate p and the weight of
+	// the second, measured between tile centers and clamped at the outer half-tiles
+	nearestTiles := func(p, n, tiles int) (int, int, float64) {
+		f := (float64(p)+0.5)*float64(tiles)/float64(n) - 0.5
+		if f <= 0 {
+			return 0, 0, 0
+		}
+		if f >= float64(tiles-1) {
+			return tiles - 1, tiles - 1, 0
+		}
+		t0 := int(f)
+		return t0, t0 + 1, f - float64(t0)
+	}
+
+	resultData := make([]uint8, len(srcData))
+	parallelRows(height, func(startY, endY int) {
+		for y := startY; y < endY; y++ {
+			ty0, ty1, wy := nearestTiles(y, height, tilesY)
+			for x := 0; x < width; x++ {
+				tx0, tx1, wx := nearestTiles(x, width, tilesX)
+				i := y*width + x
+				v := bins[i]
+				top := mappings[ty0*tilesX+tx0][v]*(1-wx) + mappings[ty0*tilesX+tx1][v]*wx
+				bottom := mappings[ty1*tilesX+tx0][v]*(1-wx) + mappings[ty1*tilesX+tx1][v]*wx
+				equalized := top*(1-wy) + bottom*wy
+
+				// Shift the exact Y by the change to its bin, as applyFilterLuma does
+				r, g, b := yCbCrToRGB(yPlane[i]+equalized-float64(v), cbPlane[i], crPlane[i])
+				idx := i * 4
+				resultData[idx] = uint8(clampFloat64(r+0.5, 0, 255))
+				resultData[idx+1] = uint8(clampFloat64(g+0.5, 0, 255))
+				resultData[idx+2] = uint8(clampFloat64(b+0.5, 0, 255))
+				resultData[idx+3] = srcData[idx+3]
+			}
+		}
+	})
+	return resultData, nil
+}
+
+// selectiveColorRanges lists the color ranges adjusted by "selective-color", in the order
+// of selectiveColorWeights' results.
+var selectiveColorRanges = []string{"reds", "yellows", "greens", "cyans", "blues", "magentas", "neutrals"}
+
+// selectiveColorWeights returns how strongly a pixel (channels in 0-1) belongs to each of
+// selectiveColorRanges. A primary range (reds, greens, blues) applies when its channel is
+// the largest, weighted by how far it leads the middle channel; a secondary range (cyans,
+// magentas, yellows) applies when its complementary channel is the smallest, weighted by
+// how far the middle channel leads it. Neutrals peak at mid-gray and fade toward pure
+// colors, black, and white. A pure primary therefore belongs to its own range only.
+func selectiveColorWeights(rgb [3]float64) [7]float64 {
+	hi := math.Max(rgb[0], math.Max(rgb[1], rgb[2]))
+	lo := math.Min(rgb[0], math.Min(rgb[1], rgb[2]))
+	mid := rgb[0] + rgb[1] + rgb[2] - hi - lo
+
+	var w [7]float64
+	// Primary c and its complementary secondary sit at indices 2c and (2c+3)%6
+	for c := 0; c < 3; c++ {
+		if rgb[c] == hi && hi > mid {
+			w[2*c] = hi - mid
+		}
+		if rgb[c] == lo && mid > lo {
+			w[(2*c+3)%6] = mid - lo
+		}
+	}
+	w[6] = math.Max(0, 1-(math.Abs(hi-0.5)+math.Abs(lo-0.5)))
+	return w
+}
+
+// applySelectiveColor adjusts color ranges independently, like the Selective Color tool
+// of photo editors (internal logic for "selective-color"). Each range parameter is a
+// [cyan, magenta, yellow, black] array of percentages in -100..100. A pixel's cyan,
+// magenta, and yellow components are 1-R, 1-G, and 1-B; each is shifted by its own amount
+// plus the black amount, scaled by the pixel's weight in the range (see
+// selectiveColorWeights) and summed over all ranges. In "relative" mode shifts are also
+// scaled by the component's current value, so white stays white; "absolute" mode shifts
+// by the full amount. Alpha passes through.
+func applySelectiveColor(srcData []uint8, width, height int, params filterParams) ([]uint8, error) {
+	var adjustments [7][4]float64
+	for r, name := range selectiveColorRanges {
+		values := params.array(name)
+		if len(values) != 4 {
+			return nil, fmt.Errorf("Invalid parameter %q for filter 'selective-color': expected a [cyan, magenta, yellow, black] array", name)
+		}
+		for i, v := range values {
+			if v < -100 || v > 100 {
+				return nil, fmt.Errorf("Invalid parameter %q for filter 'selective-color': components must be between -100 and 100", name)
+			}
+			adjustments[r][i] = v / 100
+		}
+	}
+	relative := params.str("method") == "relative"
+	logDebug("Applying selective color (relative: %v)...", relative)
+
+	resultData := make([]uint8, len(srcData))
+	parallelRows(height, func(startY, endY int) {
+		for i := startY * width * 4; i < endY*width*4; i += 4 {
+			rgb := [3]float64{float64(srcData[i]) / 255, float64(srcData[i+1]) / 255, float64(srcData[i+2]) / 255}
+			weights := selectiveColorWeights(rgb)
+			for c := 0; c < 3; c++ {
+				ink := 1 - rgb[c]
+				shift := 0.0
+				for r, w := range weights {
+					if w > 0 {
+						shift += w * (adjustments[r][c] + adjustments[r][3])
+					}
+				}
+				if relative {
+					shift *= ink
+				}
+				ink = clampFloat64(ink+shift, 0, 1)
+				resultData[i+c] = uint8((1-ink)*255 + 0.5)
+			}
+			resultData[i+3] = srcData[i+3]
+		}
+	})
+	return resultData, nil
+}
+
+// isSkinTone reports whether a color falls inside the YCbCr skin-tone cluster of Chai and
+// Ngan (1999): 77 <= Cb <= 127 and 133 <= Cr <= 173. Luminance is ignored, which keeps
+// the test usable across lighting but also accepts some skin-colored backgrounds.
+func isSkinTone(r, g, b uint8) bool {
+	_, cb, cr := rgbToYCbCr(float64(r), float64(g), float64(b))
+	return cb >= 77 && cb <= 127 && cr >= 133 && cr <= 173
+}
+
+// applySkinDetect classifies pixels as skin with isSkinTone (internal logic for
+// "skin-detect"). In "mask" mode the output is opaque white for skin and black elsewhere;
+// in "overlay" mode skin keeps its color and everything else is shown as darkened gray so
+// the detected regions stand out. Overlay alpha passes through.
+func applySkinDetect(srcData []uint8, width, height int, params filterParams) ([]uint8, error) {
+	mask := params.str("output") == "mask"
+	logDebug("Detecting skin tones (output %s)...", params.str("output"))
+
+	resultData := make([]uint8, len(srcData))
+	parallelRows(height, func(startY, endY int) {
+		for i := startY * width * 4; i < endY*width*4; i += 4 {
+			skin := isSkinTone(srcData[i], srcData[i+1], srcData[i+2])
+			switch {
+			case mask:
+				v := uint8(0)
+				if skin {
+					v = 255
+				}
+				resultData[i], resultData[i+1], resultData[i+2], resultData[i+3] = v, v, v, 255
+			case skin:
+				copy(resultData[i:i+4], srcData[i:i+4])
+			default:
+				v := uint8(luminance(srcData[i], srcData[i+1], srcData[i+2])*0.4 + 0.5)
+				resultData[i], resultData[i+1], resultData[i+2], resultData[i+3] = v, v, v, srcData[i+3]
+			}
+		}
+	})
+	return resultData, nil
+}
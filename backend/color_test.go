@@ -0,0 +1,325 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestApplyFilterLumaKeepsChroma(t *testing.T) {
+	width, height := 16, 8
+	orange, blue := [4]uint8{180, 110, 70, 255}, [4]uint8{70, 110, 170, 255}
+	src := splitImage(width, height, 8, orange, blue)
+	got, err := applyFilterLuma(src, width, height, "sharpen", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			idx := (y*width + x) * 4
+			if x < 7 || x > 8 {
+				// Flat regions are left alone
+				if diff := maxAbsDiff(got[idx:idx+4], src[idx:idx+4]); diff > 1 {
+					t.Fatalf("(%d, %d): flat pixel changed by %d", x, y, diff)
+				}
+				continue
+			}
+			// Along the edge only luma changes
+			_, cb, cr := rgbToYCbCr(float64(src[idx]), float64(src[idx+1]), float64(src[idx+2]))
+			_, gotCb, gotCr := rgbToYCbCr(float64(got[idx]), float64(got[idx+1]), float64(got[idx+2]))
+			if math.Abs(gotCb-cb) > 1.5 || math.Abs(gotCr-cr) > 1.5 {
+				t.Fatalf("(%d, %d): chroma moved from (%.1f, %.1f) to (%.1f, %.1f)", x, y, cb, cr, gotCb, gotCr)
+			}
+		}
+	}
+	if maxAbsDiff(got, src) == 0 {
+		t.Error("sharpening left the edge unchanged")
+	}
+}
+
+func TestMonotoneCubicLUT(t *testing.T) {
+	identity := monotoneCubicLUT([]float64{0, 255}, []float64{0, 255})
+	for v, got := range identity {
+		if int(got) != v {
+			t.Fatalf("identity curve maps %d to %d", v, got)
+		}
+	}
+
+	// A steep S-curve that a plain cubic spline would overshoot
+	lut := monotoneCubicLUT([]float64{0, 100, 110, 255}, []float64{0, 10, 245, 255})
+	for v := 1; v < 256; v++ {
+		if lut[v] < lut[v-1] {
+			t.Fatalf("curve decreases from %d at %d to %d at %d", lut[v-1], v-1, lut[v], v)
+		}
+	}
+	if lut[100] != 10 || lut[110] != 245 {
+		t.Errorf("curve misses its control points: %d at 100, %d at 110", lut[100], lut[110])
+	}
+}
+
+func TestSplineCurveValidation(t *testing.T) {
+	src := randomImage(4, 4, 1)
+	for _, points := range [][]float64{
+		{0, 0},
+		{0, 0, 255},
+		{0, 0, 300, 255},
+		{0, 0, 128, 128, 128, 200, 255, 255},
+		{0, 0, 200, 128, 100, 200},
+	} {
+		if _, err := applyFilter(src, 4, 4, "spline-curve", filterParams{"points": points}); err == nil {
+			t.Errorf("points %v were accepted", points)
+		}
+	}
+}
+
+func TestDuotone(t *testing.T) {
+	width, height := 8, 4
+	src := splitImage(width, height, 4, [4]uint8{0, 0, 0, 255}, [4]uint8{255, 255, 255, 128})
+	shadow, highlight := []float64{20, 40, 90}, []float64{250, 220, 120}
+	got, err := applyFilter(src, width, height, "duotone", filterParams{"shadow": shadow, "highlight": highlight})
+	if err != nil {
+		t.Fatal(err)
+	}
+	black, white := got[:4], got[(width-1)*4:width*4]
+	if want := []uint8{20, 40, 90, 255}; maxAbsDiff(black, want) != 0 {
+		t.Errorf("black became %v, want %v", black, want)
+	}
+	if want := []uint8{250, 220, 120, 128}; maxAbsDiff(white, want) != 0 {
+		t.Errorf("white became %v, want %v", white, want)
+	}
+
+	if _, err := applyFilter(src, width, height, "duotone", filterParams{"shadow": []float64{0, 0}, "highlight": highlight}); err == nil {
+		t.Error("a two-component shadow color was accepted")
+	}
+}
+
+func TestSelectiveColorTargetsRange(t *testing.T) {
+	width, height := 8, 2
+	red, blue := [4]uint8{220, 40, 40, 255}, [4]uint8{40, 40, 220, 255}
+	src := splitImage(width, height, 4, red, blue)
+	got, err := applyFilter(src, width, height, "selective-color", filterParams{"reds": []float64{50, 0, 0, 0}, "method": "absolute"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := got[:4]; p[0] >= red[0] || p[1] != red[1] || p[2] != red[2] {
+		t.Errorf("red became %v, want less red and nothing else changed", p)
+	}
+	if p := got[(width-1)*4:]; maxAbsDiff(p[:4], blue[:]) != 0 {
+		t.Errorf("blue became %v, want it unchanged", p[:4])
+	}
+
+	// In relative mode white has no ink to scale, so even a neutrals shift leaves it alone
+	white := solidImage(2, 2, [4]uint8{255, 255, 255, 255})
+	if got, err = applyFilter(white, 2, 2, "selective-color", filterParams{"neutrals": []float64{40, 40, 40, 40}}); err != nil {
+		t.Fatal(err)
+	}
+	if maxAbsDiff(got, white) != 0 {
+		t.Errorf("relative neutrals shift changed white to %v", got[:4])
+	}
+}
+
+func TestSkinDetect(t *testing.T) {
+	for _, tc := range []struct {
+		rgb  [3]uint8
+		skin bool
+	}{
+		{[3]uint8{224, 172, 140}, true}, // Light skin
+		{[3]uint8{141, 85, 36}, true},   // Dark skin
+		{[3]uint8{40, 60, 200}, false},  // Blue
+		{[3]uint8{60, 160, 70}, false},  // Green
+		{[3]uint8{128, 128, 128}, false},
+	} {
+		if got := isSkinTone(tc.rgb[0], tc.rgb[1], tc.rgb[2]); got != tc.skin {
+			t.Errorf("isSkinTone(%v) = %v, want %v", tc.rgb, got, tc.skin)
+		}
+	}
+
+	skin, blue := [4]uint8{224, 172, 140, 255}, [4]uint8{40, 60, 200, 100}
+	src := splitImage(4, 1, 2, skin, blue)
+	got, err := applyFilter(src, 4, 1, "skin-detect", filterParams{"output": "mask"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []uint8{255, 255, 255, 255, 255, 255, 255, 255, 0, 0, 0, 255, 0, 0, 0, 255}; maxAbsDiff(got, want) != 0 {
+		t.Errorf("mask is %v, want %v", got, want)
+	}
+	if got, err = applyFilter(src, 4, 1, "skin-detect", nil); err != nil {
+		t.Fatal(err)
+	}
+	if maxAbsDiff(got[:8], src[:8]) != 0 || got[8] != got[9] || got[8] != got[10] || got[11] != blue[3] {
+		t.Errorf("overlay is %v, want skin kept and blue darkened to gray", got)
+	}
+}
+
+func TestGrayscaleWeights(t *testing.T) {
+	red := solidImage(1, 1, [4]uint8{255, 0, 0, 200})
+	for weights, want := range map[string]uint8{"rec601": 76, "rec709": 54} {
+		got, err := applyFilter(red, 1, 1, "grayscale", filterParams{"weights": weights})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got[0] != want || got[1] != want || got[2] != want || got[3] != 200 {
+			t.Errorf("%s: pure red became %v, want gray %d with alpha 200", weights, got, want)
+		}
+	}
+}
+
+func TestInvertTwiceIsIdentity(t *testing.T) {
+	width, height := 9, 7
+	src := randomImage(width, height, 10)
+	for i := 3; i < len(src); i += 8 {
+		src[i] = uint8(i)
+	}
+	once, err := applyFilter(src, width, height, "invert", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range src {
+		want := 255 - src[i]
+		if i%4 == 3 {
+			want = src[i]
+		}
+		if once[i] != want {
+			t.Fatalf("byte %d: inverted %d to %d, want %d", i, src[i], once[i], want)
+		}
+	}
+	twice, err := applyFilter(once, width, height, "invert", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if maxAbsDiff(twice, src) != 0 {
+		t.Error("inverting twice did not restore the original")
+	}
+}
+
+func TestSepiaMidGray(t *testing.T) {
+	src := solidImage(2, 1, [4]uint8{128, 128, 128, 90})
+	got, err := applyFilter(src, 2, 1, "sepia", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Row sums 1.351, 1.203, and 0.937 times 128
+	if want := []uint8{173, 154, 120, 90}; maxAbsDiff(got[:4], want) != 0 {
+		t.Errorf("mid-gray became %v, want %v", got[:4], want)
+	}
+
+	white := solidImage(1, 1, [4]uint8{255, 255, 255, 255})
+	if got, err = applyFilter(white, 1, 1, "sepia", nil); err != nil {
+		t.Fatal(err)
+	}
+	if want := []uint8{255, 255, 239, 255}; maxAbsDiff(got, want) != 0 {
+		t.Errorf("white became %v, want %v with red and green clamped", got, want)
+	}
+}
+
+func TestHistEq(t *testing.T) {
+	// A gray gradient squeezed into 100-140
+	width, height := 41, 4
+	src := make([]uint8, width*height*4)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			v := uint8(100 + x)
+			copy(src[(y*width+x)*4:], []uint8{v, v, v, 255})
+		}
+	}
+	got, err := applyFilter(src, width, height, "histeq", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lo, hi := got[0], got[0]
+	for i := 0; i < len(got); i += 4 {
+		lo, hi = uint8(min(int(lo), int(got[i]))), max(hi, got[i])
+		if got[i] != got[i+1] || got[i] != got[i+2] {
+			t.Fatalf("pixel %d became %v, no longer gray", i/4, got[i:i+4])
+		}
+	}
+	if lo > 5 || hi < 250 {
+		t.Errorf("equalized gradient spans %d-%d, want nearly 0-255", lo, hi)
+	}
+
+	flat := solidImage(6, 6, [4]uint8{90, 120, 30, 255})
+	if got, err = applyFilter(flat, 6, 6, "histeq", nil); err != nil {
+		t.Fatal(err)
+	}
+	if maxAbsDiff(got, flat) != 0 {
+		t.Error("a constant image was changed")
+	}
+}
+
+func TestProtanopiaShiftsRed(t *testing.T) {
+	src := []uint8{255, 0, 0, 255, 128, 128, 128, 200}
+	got, err := applyFilter(src, 2, 1, "colorblind", filterParams{"type": "protanopia"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Without L cones, pure red is seen as a dim olive
+	if red := got[:4]; red[0] > 155 || red[1] < 50 || red[3] != 255 {
+		t.Errorf("pure red became %v, want R down by at least 100 and G up by at least 50", red)
+	}
+	if diff := maxAbsDiff(got[4:], src[4:]); diff > 2 {
+		t.Errorf("mid-gray became %v, changed by %d", got[4:], diff)
+	}
+}
+
+func TestCLAHEBoostsContrastWithoutSeams(t *testing.T) {
+	// A faint gray ramp (100-140) with ±4 gray texture, split into 4x4 tiles of 16 pixels
+	size, tile := 64, 16
+	src := make([]uint8, size*size*4)
+	rng := rand.New(rand.NewSource(6))
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			v := uint8(100 + x*40/size + rng.Intn(9) - 4)
+			copy(src[(y*size+x)*4:], []uint8{v, v, v, 255})
+		}
+	}
+	got, err := applyFilter(src, size, size, "clahe", filterParams{"tiles": 4.0, "clipLimit": 3.0})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// localStdDev averages the standard deviation of 8x8 blocks
+	localStdDev := func(data []uint8) float64 {
+		total := 0.0
+		for by := 0; by < size; by += 8 {
+			for bx := 0; bx < size; bx += 8 {
+				sum, sumSq := 0.0, 0.0
+				for y := by; y < by+8; y++ {
+					for x := bx; x < bx+8; x++ {
+						v := float64(data[(y*size+x)*4])
+						sum, sumSq = sum+v, sumSq+v*v
+					}
+				}
+				mean := sum / 64
+				total += math.Sqrt(sumSq/64 - mean*mean)
+			}
+		}
+		return total / float64(size*size/64)
+	}
+	if before, after := localStdDev(src), localStdDev(got); after < 1.5*before {
+		t.Errorf("local standard deviation went from %.2f to %.2f, want it raised by half", before, after)
+	}
+
+	// columnJump is the mean step between columns x-1 and x
+	columnJump := func(x int) float64 {
+		sum := 0
+		for y := 0; y < size; y++ {
+			sum += abs(int(got[(y*size+x)*4]) - int(got[(y*size+x-1)*4]))
+		}
+		return float64(sum) / float64(size)
+	}
+	interior := 0.0
+	for x := 1; x < size; x++ {
+		if x%tile != 0 {
+			interior = math.Max(interior, columnJump(x))
+		}
+	}
+	for x := tile; x < size; x += tile {
+		if jump := columnJump(x); jump > interior {
+			t.Errorf("step across the tile boundary at x=%d is %.2f, above the largest in-tile step %.2f", x, jump, interior)
+		}
+	}
+}
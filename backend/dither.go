@@ -0,0 +1,77 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import (
+	"math"
+	"syscall/js"
+	"time"
+)
+
+// ditherFloydSteinbergWrapper wraps the ditherFloydSteinberg logic for syscall/js
+// interaction.
+// It expects imageData { width, height, data: Uint8ClampedArray } and the number of levels
+// per channel (2-256; 2 gives 1-bit color).
+// It returns the processed Uint8ClampedArray or an error object.
+func ditherFloydSteinbergWrapper(this js.Value, args []js.Value) interface{} {
+	startTime := time.Now()
+	logDebug("ditherFloydSteinbergWrapper called")
+
+	if len(args) < 2 {
+		return createError("Invalid number of arguments for ditherFloydSteinberg: expected 2 (imageData, levels)")
+	}
+	srcData, width, height, err := readImageData(args[0])
+	if err != nil {
+		return createError(err.Error())
+	}
+	if args[1].Type() != js.TypeNumber || args[1].Int() < 2 || args[1].Int() > 256 {
+		return createError("Invalid levels argument: expected a number between 2 and 256")
+	}
+
+	resultData := ditherFloydSteinberg(srcData, width, height, args[1].Int())
+
+	logInfo("ditherFloydSteinbergWrapper completed in %v", time.Since(startTime))
+	return bytesToJS(resultData)
+}
+
+// ditherFloydSteinberg reduces each RGB channel to `levels` evenly spaced values (internal
+// logic), diffusing every pixel's quantization error to the unvisited neighbors with the
+// Floyd-Steinberg weights: 7/16 right, 3/16 below-left, 5/16 below, 1/16 below-right. The
+// accumulated error keeps the local average color, so smooth gradients become a fine
+// texture of the available levels instead of bands. Alpha passes through.
+//
+// Unlike the other filters this runs serially: each pixel depends on the error pushed by
+// its left and upper neighbors, so rows cannot be split across goroutines. (A wavefront of
+// diagonal bands, each row trailing the one above by two pixels, could be parallelized,
+// but the per-pixel work is too small to pay for the synchronization.) Only the errors of
+// the current and next row are kept.
+func ditherFloydSteinberg(srcData []uint8, width, height, levels int) []uint8 {
+	logDebug("Applying Floyd-Steinberg dithering to %d levels...", levels)
+	step := 255 / float64(levels-1)
+	resultData := make([]uint8, len(srcData))
+	// Error rows are padded by one pixel on each side so the kernel needs no bounds checks
+	current := make([]float64, (width+2)*3)
+	next := make([]float64, (width+2)*3)
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			idx := (y*width + x) * 4
+			e := (x + 1) * 3
+			for c := 0; c < 3; c++ {
+				v := float64(srcData[idx+c]) + current[e+c]
+				q := clampFloat64(math.Round(v/step)*step, 0, 255)
+				resultData[idx+c] = uint8(q + 0.5)
+				diff := v - q
+				current[e+3+c] += diff * 7 / 16
+				next[e-3+c] += diff * 3 / 16
+				next[e+c] += diff * 5 / 16
+				next[e+3+c] += diff * 1 / 16
+			}
+			resultData[idx+3] = srcData[idx+3]
+		}
+		current, next = next, current
+		clear(next)
+	}
+	return resultData
+}
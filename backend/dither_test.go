@@ -0,0 +1,57 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import "testing"
+
+func TestDitherFloydSteinbergGradient(t *testing.T) {
+	width, height := 64, 32
+	src := make([]uint8, width*height*4)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			v := uint8(x * 4)
+			copy(src[(y*width+x)*4:], []uint8{v, v, v, uint8(255 - y)})
+		}
+	}
+
+	for _, levels := range []int{2, 4} {
+		got := ditherFloydSteinberg(src, width, height, levels)
+		allowed := map[uint8]bool{}
+		for l := 0; l < levels; l++ {
+			allowed[uint8(l*255/(levels-1))] = true
+		}
+		for i := 0; i < len(got); i += 4 {
+			if got[i+3] != src[i+3] {
+				t.Fatalf("%d levels: alpha of pixel %d changed", levels, i/4)
+			}
+			if !allowed[got[i]] {
+				t.Fatalf("%d levels: pixel %d has value %d", levels, i/4, got[i])
+			}
+		}
+
+		// Averaged over a few columns, the texture reproduces the gradient
+		for x0 := 0; x0 < width; x0 += 8 {
+			sum, want := 0, 0
+			for y := 0; y < height; y++ {
+				for x := x0; x < x0+8; x++ {
+					sum += int(got[(y*width+x)*4])
+					want += int(src[(y*width+x)*4])
+				}
+			}
+			if diff := abs(sum-want) / (8 * height); diff > 8 {
+				t.Errorf("%d levels: columns %d-%d average off by %d", levels, x0, x0+7, diff)
+			}
+		}
+	}
+
+	// At 1-bit, the middle of the gradient mixes black and white rather than banding
+	got := ditherFloydSteinberg(src, width, height, 2)
+	counts := map[uint8]int{}
+	for y := 0; y < height; y++ {
+		counts[got[(y*width+32)*4]]++
+	}
+	if counts[0] == 0 || counts[255] == 0 {
+		t.Errorf("the mid-gray column is a solid band: %v", counts)
+	}
+}
@@ -0,0 +1,296 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import (
+	"math"
+	"math/cmplx"
+	"syscall/js"
+	"time"
+
+	"gonum.org/v1/gonum/dsp/fourier"
+)
+
+// fft2D transforms a width x height complex plane (row-major) in place: 1D FFTs over every
+// row, then over every column. The inverse transform is normalized by 1/(width*height) so a
+// forward/inverse round trip reproduces the input. Rows and columns are processed in
+// parallel chunks, each with its own FFT workspace (fourier.CmplxFFT is not goroutine-safe).
+func fft2D(plane []complex128, width, height int, inverse bool) {
+	transform := func(fft *fourier.CmplxFFT, seq []complex128) {
+		if inverse {
+			fft.Sequence(seq, seq)
+		} else {
+			fft.Coefficients(seq, seq)
+		}
+	}
+
+	parallelRows(height, func(startY, endY int) {
+		fft := fourier.NewCmplxFFT(width)
+		for y := startY; y < endY; y++ {
+			transform(fft, plane[y*width:(y+1)*width])
+		}
+	})
+
+	// Columns are chunked the same way rows are; each gets copied into a contiguous buffer
+	parallelRows(width, func(startX, endX int) {
+		fft := fourier.NewCmplxFFT(height)
+		column := make([]complex128, height)
+		for x := startX; x < endX; x++ {
+			for y := 0; y < height; y++ {
+				column[y] = plane[y*width+x]
+			}
+			transform(fft, column)
+			for y := 0; y < height; y++ {
+				plane[y*width+x] = column[y]
+			}
+		}
+	})
+
+	if inverse {
+		scale := complex(1/float64(width*height), 0)
+		for i := range plane {
+			plane[i] *= scale
+		}
+	}
+}
+
+// fftSpectrumWrapper wraps the fftSpectrum logic for syscall/js interaction.
+// It expects imageData { width, height, data: Uint8ClampedArray }.
+// It returns the spectrum as a grayscale Uint8ClampedArray of the same size, or an error object.
+func fftSpectrumWrapper(this js.Value, args []js.Value) interface{} {
+	startTime := time.Now()
+	logDebug("fftSpectrumWrapper called")
+
+	if len(args) < 1 {
+		return createError("Invalid number of arguments for fftSpectrum: expected 1 (imageData)")
+	}
+	srcData, width, height, err := readImageData(args[0])
+	if err != nil {
+		return createError(err.Error())
+	}
+
+	resultData := fftSpectrum(srcData, width, height)
+
+	logInfo("fftSpectrumWrapper completed in %v", time.Since(startTime))
+	return bytesToJS(resultData)
+}
+
+// fftSpectrum computes the 2D FFT magnitude spectrum of the luminance channel for
+// visualization (internal logic). Magnitudes are compressed with log(1 + |F|), scaled so the
+// strongest frequency is 255, and quadrant-swapped so the zero frequency sits at the center
+// (width/2, height/2). The output is an opaque grayscale image.
+func fftSpectrum(srcData []uint8, width, height int) []uint8 {
+	luma := lumaPlane(srcData, width, height)
+	plane := make([]complex128, len(luma))
+	for i, l := range luma {
+		plane[i] = complex(l, 0)
+	}
+	fft2D(plane, width, height, false)
+
+	logMag := make([]float64, len(plane))
+	maxMag := 0.0
+	for i, v := range plane {
+		logMag[i] = math.Log1p(cmplx.Abs(v))
+		maxMag = math.Max(maxMag, logMag[i])
+	}
+	if maxMag == 0 {
+		maxMag = 1
+	}
+
+	resultData := make([]uint8, len(srcData))
+	parallelRows(height, func(startY, endY int) {
+		for y := startY; y < endY; y++ {
+			// Shift so that output (width/2, height/2) shows frequency (0, 0)
+			fy := (y + height - height/2) % height
+			for x := 0; x < width; x++ {
+				fx := (x + width - width/2) % width
+				v := uint8(clampFloat64(logMag[fy*width+fx]/maxMag*255+0.5, 0, 255))
+				idx := (y*width + x) * 4
+				resultData[idx], resultData[idx+1], resultData[idx+2], resultData[idx+3] = v, v, v, 255
+			}
+		}
+	})
+	return resultData
+}
+
+// applyFFTPass applies an ideal low-pass or high-pass filter in the frequency domain
+// (internal logic for "fft-lowpass" and "fft-highpass"). Each RGB channel is transformed,
+// every coefficient whose distance from the zero frequency is beyond (low-pass) or within
+// (high-pass) `radius` bins is zeroed, and the channel is inverse-transformed. Because a
+// high-pass removes the mean, its output is offset by 128 so that negative responses stay
+// visible. Alpha passes through.
+func applyFFTPass(srcData []uint8, width, height int, params filterParams, highpass bool) ([]uint8, error) {
+	radius := params.num("radius")
+	logDebug("Applying FFT pass filter (radius %.1f, highpass %v)...", radius, highpass)
+
+	// Signed frequency index for position i of an n-point FFT
+	signedFreq := func(i, n int) float64 {
+		if i > n/2 {
+			return float64(i - n)
+		}
+		return float64(i)
+	}
+	bias := 0.0
+	if highpass {
+		bias = 128
+	}
+
+	resultData := make([]uint8, len(srcData))
+	plane := make([]complex128, width*height)
+	for c := 0; c < 3; c++ {
+		for i := range plane {
+			plane[i] = complex(float64(srcData[i*4+c]), 0)
+		}
+		fft2D(plane, width, height, false)
+
+		for y := 0; y < height; y++ {
+			fy := signedFreq(y, height)
+			for x := 0; x < width; x++ {
+				inside := math.Hypot(signedFreq(x, width), fy) <= radius
+				if inside == highpass {
+					plane[y*width+x] = 0
+				}
+			}
+		}
+
+		fft2D(plane, width, height, true)
+		for i, v := range plane {
+			resultData[i*4+c] = uint8(clampFloat64(real(v)+bias+0.5, 0, 255))
+		}
+	}
+	for i := 3; i < len(srcData); i += 4 {
+		resultData[i] = srcData[i]
+	}
+
+	logDebug("FFT pass filter complete.")
+	return resultData, nil
+}
+
+// saliencyMapWrapper wraps the saliencyMap logic for syscall/js interaction.
+// It expects imageData { width, height, data: Uint8ClampedArray }.
+// It returns the saliency as a grayscale Uint8ClampedArray of the same size, or an error object.
+func saliencyMapWrapper(this js.Value, args []js.Value) interface{} {
+	startTime := time.Now()
+	logDebug("saliencyMapWrapper called")
+
+	if len(args) < 1 {
+		return createError("Invalid number of arguments for saliencyMap: expected 1 (imageData)")
+	}
+	srcData, width, height, err := readImageData(args[0])
+	if err != nil {
+		return createError(err.Error())
+	}
+
+	resultData, err := saliencyMap(srcData, width, height)
+	if err != nil {
+		return createError(err.Error())
+	}
+
+	logInfo("saliencyMapWrapper completed in %v", time.Since(startTime))
+	return bytesToJS(resultData)
+}
+
+// saliencyWorkingSize is the largest side, in pixels, at which saliencyMap analyzes the
+// image. The spectral residual responds to object-scale structure, which survives the
+// reduction, and the small FFT keeps the method cheap.
+const saliencyWorkingSize = 64
+
+// saliencyMap estimates visually prominent regions with the spectral residual method of
+// Hou and Zhang (2007) (internal logic). The image is box-downsampled so its larger side is
+// at most saliencyWorkingSize, and the luminance is transformed. The log amplitude spectrum
+// minus its 3x3 local average (the "residual", which is what stands out from the smooth
+// spectrum natural images share) is recombined with the original phase and inverse
+// transformed; the squared magnitude is the raw saliency. It is smoothed with three "blur"
+// passes, normalized so the most salient point is 255, and scaled back up to the input
+// size. The output is an opaque grayscale image.
+func saliencyMap(srcData []uint8, width, height int) ([]uint8, error) {
+	small, sw, sh := srcData, width, height
+	if factor := (max(width, height) + saliencyWorkingSize - 1) / saliencyWorkingSize; factor > 1 {
+		small, sw, sh = downsampleBox(srcData, width, height, factor)
+	}
+	logDebug("Computing spectral residual saliency at %dx%d", sw, sh)
+
+	luma := lumaPlane(small, sw, sh)
+	plane := make([]complex128, len(luma))
+	for i, l := range luma {
+		plane[i] = complex(l, 0)
+	}
+	fft2D(plane, sw, sh, false)
+
+	// Coefficients that are numerically zero (common in flat synthetic images) have no
+	// meaningful phase and are left out of the reconstruction
+	amp := make([]float64, len(plane))
+	maxAmp := 0.0
+	for i, v := range plane {
+		amp[i] = cmplx.Abs(v)
+		maxAmp = math.Max(maxAmp, amp[i])
+	}
+	floor := maxAmp * 1e-9
+	logAmp := make([]float64, len(plane))
+	for i, a := range amp {
+		if a > floor {
+			logAmp[i] = math.Log(a)
+		}
+	}
+	for y := 0; y < sh; y++ {
+		for x := 0; x < sw; x++ {
+			i := y*sw + x
+			if amp[i] <= floor {
+				plane[i] = 0
+				continue
+			}
+			// The spectrum is periodic, so the local average wraps around the edges
+			sum, count := 0.0, 0
+			for dy := -1; dy <= 1; dy++ {
+				for dx := -1; dx <= 1; dx++ {
+					if j := ((y+dy+sh)%sh)*sw + (x+dx+sw)%sw; amp[j] > floor {
+						sum += logAmp[j]
+						count++
+					}
+				}
+			}
+			plane[i] = cmplx.Rect(math.Exp(logAmp[i]-sum/float64(count)), cmplx.Phase(plane[i]))
+		}
+	}
+	fft2D(plane, sw, sh, true)
+
+	raw := make([]float64, len(plane))
+	maxRaw := 0.0
+	for i, v := range plane {
+		raw[i] = real(v)*real(v) + imag(v)*imag(v)
+		maxRaw = math.Max(maxRaw, raw[i])
+	}
+	if maxRaw == 0 {
+		maxRaw = 1
+	}
+
+	saliency := make([]uint8, sw*sh*4)
+	for i, v := range raw {
+		g := uint8(clampFloat64(v/maxRaw*255+0.5, 0, 255))
+		saliency[i*4], saliency[i*4+1], saliency[i*4+2], saliency[i*4+3] = g, g, g, 255
+	}
+	for pass := 0; pass < 3; pass++ {
+		var err error
+		if saliency, err = applyFilter(saliency, sw, sh, "blur", filterParams{}); err != nil {
+			return nil, err
+		}
+	}
+
+	// Stretch the smoothed map back to the full 0-255 range
+	var peak uint8
+	for i := 0; i < len(saliency); i += 4 {
+		peak = max(peak, saliency[i])
+	}
+	if peak > 0 {
+		for i := 0; i < len(saliency); i += 4 {
+			g := uint8((int(saliency[i])*255 + int(peak)/2) / int(peak))
+			saliency[i], saliency[i+1], saliency[i+2] = g, g, g
+		}
+	}
+
+	if sw == width && sh == height {
+		return saliency, nil
+	}
+	return resizeBilinear(saliency, sw, sh, width, height), nil
+}
@@ -0,0 +1,126 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import (
+	"math"
+	"math/cmplx"
+	"math/rand"
+	"testing"
+)
+
+func TestFFT2DRoundTrip(t *testing.T) {
+	width, height := 12, 10 // Not powers of two
+	rng := rand.New(rand.NewSource(1))
+	plane := make([]complex128, width*height)
+	for i := range plane {
+		plane[i] = complex(rng.Float64()*255, 0)
+	}
+	original := append([]complex128(nil), plane...)
+
+	fft2D(plane, width, height, false)
+	if dc := plane[0]; math.Abs(imag(dc)) > 1e-9 {
+		t.Errorf("DC coefficient %v of a real plane is not real", dc)
+	}
+	fft2D(plane, width, height, true)
+	for i := range plane {
+		if cmplx.Abs(plane[i]-original[i]) > 1e-9 {
+			t.Fatalf("round trip changed element %d from %v to %v", i, original[i], plane[i])
+		}
+	}
+}
+
+func TestFFTSpectrumSinusoid(t *testing.T) {
+	// A horizontal sinusoid with 4 cycles across the width
+	width, height, cycles := 32, 16, 4
+	src := make([]uint8, width*height*4)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			v := uint8(128 + 100*math.Cos(2*math.Pi*float64(cycles*x)/float64(width)))
+			copy(src[(y*width+x)*4:], []uint8{v, v, v, 255})
+		}
+	}
+	got := fftSpectrum(src, width, height)
+
+	at := func(x, y int) uint8 { return got[(y*width+x)*4] }
+	cx, cy := width/2, height/2
+	left, right := at(cx-cycles, cy), at(cx+cycles, cy)
+	if left != right {
+		t.Errorf("spots are not symmetric: %d and %d", left, right)
+	}
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if (x == cx-cycles || x == cx+cycles || x == cx) && y == cy {
+				continue
+			}
+			if at(x, y) >= left/2 {
+				t.Fatalf("(%d, %d) is %d, want well below the spots' %d", x, y, at(x, y), left)
+			}
+		}
+	}
+}
+
+func TestFFTPass(t *testing.T) {
+	width, height := 20, 14
+	src := randomImage(width, height, 3)
+	got, err := applyFilter(src, width, height, "fft-lowpass", filterParams{"radius": 1000.0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := maxAbsDiff(got, src); diff > 1 {
+		t.Errorf("low-pass with a huge cutoff changed the image by %d", diff)
+	}
+
+	// A high-pass removes the flat color entirely, leaving the 128 offset
+	flat := solidImage(width, height, [4]uint8{30, 90, 200, 255})
+	if got, err = applyFilter(flat, width, height, "fft-highpass", filterParams{"radius": 2.0}); err != nil {
+		t.Fatal(err)
+	}
+	if diff := maxAbsDiff(got, solidImage(width, height, [4]uint8{128, 128, 128, 255})); diff > 1 {
+		t.Errorf("high-pass of a flat image differs from mid-gray by %d", diff)
+	}
+}
+
+func TestSaliencyMapFindsObject(t *testing.T) {
+	width, height := 64, 48
+	src := solidImage(width, height, [4]uint8{90, 110, 100, 255})
+	rng := rand.New(rand.NewSource(4))
+	for i := 0; i < len(src); i += 4 {
+		for c := 0; c < 3; c++ {
+			src[i+c] += uint8(rng.Intn(5))
+		}
+	}
+	for y := 12; y < 20; y++ {
+		for x := 40; x < 48; x++ {
+			copy(src[(y*width+x)*4:], []uint8{240, 200, 40, 255})
+		}
+	}
+
+	got, err := saliencyMap(src, width, height)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var inside, outside float64
+	var peak uint8
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			idx := (y*width + x) * 4
+			if got[idx+3] != 255 || got[idx] != got[idx+1] || got[idx] != got[idx+2] {
+				t.Fatalf("(%d, %d) is %v, want opaque gray", x, y, got[idx:idx+4])
+			}
+			peak = max(peak, got[idx])
+			if x >= 40 && x < 48 && y >= 12 && y < 20 {
+				inside += float64(got[idx]) / 64
+			} else {
+				outside += float64(got[idx]) / float64(width*height-64)
+			}
+		}
+	}
+	if peak != 255 {
+		t.Errorf("peak saliency %d, want 255", peak)
+	}
+	if inside < 3*outside {
+		t.Errorf("mean saliency %.1f on the object and %.1f elsewhere, want the object to stand out", inside, outside)
+	}
+}
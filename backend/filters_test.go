@@ -0,0 +1,297 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import (
+	"bytes"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// splitImage returns an image whose columns left of splitX are c1 and the rest c2: a
+// vertical edge at splitX.
+func splitImage(width, height, splitX int, c1, c2 [4]uint8) []uint8 {
+	data := make([]uint8, width*height*4)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			c := c2
+			if x < splitX {
+				c = c1
+			}
+			copy(data[(y*width+x)*4:], c[:])
+		}
+	}
+	return data
+}
+
+// spread returns the difference between the largest and smallest RGB value of a pixel, a
+// simple measure of saturation.
+func spread(p []uint8) int {
+	return int(max(p[0], p[1], p[2])) - int(min(int(p[0]), min(int(p[1]), int(p[2]))))
+}
+
+func TestDefringe(t *testing.T) {
+	width, height := 12, 6
+	black, white := [4]uint8{0, 0, 0, 255}, [4]uint8{255, 255, 255, 255}
+
+	neutral := splitImage(width, height, 6, black, white)
+	got, err := applyFilter(neutral, width, height, "defringe", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, neutral) {
+		t.Error("defringe changed a neutral image")
+	}
+
+	// A magenta column along the edge of a black/white split, as a lens would leave it
+	fringed := splitImage(width, height, 6, black, white)
+	for y := 0; y < height; y++ {
+		copy(fringed[(y*width+6)*4:], []uint8{200, 40, 220, 255})
+	}
+	if got, err = applyFilter(fringed, width, height, "defringe", nil); err != nil {
+		t.Fatal(err)
+	}
+	for y := 0; y < height; y++ {
+		idx := (y*width + 6) * 4
+		if before, after := spread(fringed[idx:]), spread(got[idx:]); after > before/4 {
+			t.Errorf("row %d: fringe spread went from %d to %d, want it mostly desaturated", y, before, after)
+		}
+	}
+}
+
+// noisyEdgeImage returns a gray split image (50 left of splitX, 200 from it on) with seeded
+// uniform noise of ±amplitude, along with the clean image.
+func noisyEdgeImage(width, height, splitX, amplitude int, seed int64) ([]uint8, []uint8) {
+	clean := splitImage(width, height, splitX, [4]uint8{50, 50, 50, 255}, [4]uint8{200, 200, 200, 255})
+	noisy := append([]uint8(nil), clean...)
+	rng := rand.New(rand.NewSource(seed))
+	for i := range noisy {
+		if i%4 != 3 {
+			noisy[i] = uint8(clamp(int(noisy[i])+rng.Intn(2*amplitude+1)-amplitude, 0, 255))
+		}
+	}
+	return noisy, clean
+}
+
+// columnError returns the mean absolute difference between a and b over columns
+// [fromX, toX).
+func columnError(a, b []uint8, width, height, fromX, toX int) float64 {
+	sum := 0
+	for y := 0; y < height; y++ {
+		for x := fromX; x < toX; x++ {
+			for c := 0; c < 3; c++ {
+				i := (y*width+x)*4 + c
+				sum += abs(int(a[i]) - int(b[i]))
+			}
+		}
+	}
+	return float64(sum) / float64(height*(toX-fromX)*3)
+}
+
+func TestNLMeansSmoothsNoiseKeepsEdge(t *testing.T) {
+	width, height := 24, 16
+	noisy, clean := noisyEdgeImage(width, height, 12, 20, 1)
+	denoised, err := applyFilter(noisy, width, height, "nlmeans", filterParams{"strength": 25.0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	blurred, err := applyFilter(noisy, width, height, "blur", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flatBefore := columnError(noisy, clean, width, height, 0, 8)
+	if flatAfter := columnError(denoised, clean, width, height, 0, 8); flatAfter > flatBefore/2 {
+		t.Errorf("flat-region error went from %.1f to %.1f, want at least halved", flatBefore, flatAfter)
+	}
+	nlEdge := columnError(denoised, clean, width, height, 11, 13)
+	blurEdge := columnError(blurred, clean, width, height, 11, 13)
+	if nlEdge >= blurEdge {
+		t.Errorf("edge error %.1f is not below the box blur's %.1f", nlEdge, blurEdge)
+	}
+}
+
+func TestProtectSharpen(t *testing.T) {
+	width, height := 24, 12
+	noisy, _ := noisyEdgeImage(width, height, 12, 4, 2)
+	got, err := applyFilter(noisy, width, height, "protect-sharpen", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain, err := applyFilter(noisy, width, height, "sharpen", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Noise in the flat areas stays well below the threshold, so it is left alone
+	if diff := columnError(got, noisy, width, height, 0, 9); diff > 0.5 {
+		t.Errorf("flat region changed by %.2f on average", diff)
+	}
+	if diff := columnError(plain, noisy, width, height, 0, 9); diff < 2 {
+		t.Errorf("plain sharpening changed the flat region by only %.2f; the test image is not noisy enough", diff)
+	}
+
+	// The edge gains contrast: darker on the dark side, brighter on the bright side
+	for y := 0; y < height; y++ {
+		dark, bright := (y*width+11)*4, (y*width+12)*4
+		if got[dark] >= noisy[dark] || got[bright] <= noisy[bright] {
+			t.Errorf("row %d: edge went from %d|%d to %d|%d, want more contrast", y, noisy[dark], noisy[bright], got[dark], got[bright])
+		}
+	}
+}
+
+func TestGradientDirection(t *testing.T) {
+	width, height := 10, 10
+	black, white := [4]uint8{0, 0, 0, 255}, [4]uint8{255, 255, 255, 255}
+	vertical := splitImage(width, height, 5, black, white)
+	// The same edge turned on its side: dark above row 5, bright below
+	horizontal := make([]uint8, len(vertical))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			copy(horizontal[(y*width+x)*4:], vertical[(x*width+y)*4:(x*width+y)*4+4])
+		}
+	}
+
+	v, err := applyFilter(vertical, width, height, "gradient-direction", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h, err := applyFilter(horizontal, width, height, "gradient-direction", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Brighter to the right is red
+	edge := (5*width + 5) * 4
+	if got := v[edge : edge+3]; got[0] != 255 || got[1] != 0 || got[2] != 0 {
+		t.Errorf("vertical edge colored %v, want pure red", got)
+	}
+	if got := h[edge : edge+3]; got[0] == 255 && got[1] == 0 && got[2] == 0 {
+		t.Errorf("horizontal edge colored %v, the same as the vertical one", got)
+	} else if spread(got) < 200 {
+		t.Errorf("horizontal edge colored %v, want a saturated hue", got)
+	}
+	// Flat areas are black
+	if flat := v[(5*width+1)*4:]; flat[0] != 0 || flat[1] != 0 || flat[2] != 0 {
+		t.Errorf("flat area colored %v, want black", flat[:3])
+	}
+}
+
+func TestColorEmbossKeepsHue(t *testing.T) {
+	width, height := 12, 8
+	src := splitImage(width, height, 6, [4]uint8{160, 80, 40, 255}, [4]uint8{120, 60, 30, 255})
+	got, err := applyFilter(src, width, height, "emboss", filterParams{"color": true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if maxAbsDiff(got, src) == 0 {
+		t.Fatal("color emboss left the edge unchanged")
+	}
+	for i := 0; i < len(got); i += 4 {
+		wantHue, _, _ := rgbToHSL(float64(src[i]), float64(src[i+1]), float64(src[i+2]))
+		hue, _, _ := rgbToHSL(float64(got[i]), float64(got[i+1]), float64(got[i+2]))
+		if math.Abs(hue-wantHue) > 2 {
+			t.Fatalf("pixel %d: hue moved from %.1f to %.1f", i/4, wantHue, hue)
+		}
+		if got[i+3] != src[i+3] {
+			t.Fatalf("pixel %d: alpha changed", i/4)
+		}
+	}
+}
+
+func TestGlitchSeeded(t *testing.T) {
+	width, height := 40, 30
+	src := randomImage(width, height, 8)
+	run := func(seed, intensity float64) []uint8 {
+		t.Helper()
+		got, err := applyFilter(src, width, height, "glitch", filterParams{"seed": seed, "intensity": intensity})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return got
+	}
+
+	if !bytes.Equal(run(3, 0), src) {
+		t.Error("intensity 0 changed the image")
+	}
+	first := run(3, 0.7)
+	if bytes.Equal(first, src) {
+		t.Fatal("intensity 0.7 left the image unchanged")
+	}
+	if !bytes.Equal(run(3, 0.7), first) {
+		t.Error("the same seed gave a different glitch")
+	}
+	if bytes.Equal(run(4, 0.7), first) {
+		t.Error("different seeds gave the same glitch")
+	}
+}
+
+func TestDisplace(t *testing.T) {
+	width, height := 16, 10
+	src := randomImage(width, height, 9)
+	displace := func(mapColor [4]uint8, scale float64) []uint8 {
+		t.Helper()
+		dispMap := paramImage{data: solidImage(width, height, mapColor), width: width, height: height}
+		got, err := applyFilter(src, width, height, "displace", filterParams{"map": dispMap, "scale": scale})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return got
+	}
+
+	if got := displace([4]uint8{128, 128, 0, 255}, 10); !bytes.Equal(got, src) {
+		t.Error("a neutral map changed the image")
+	}
+
+	// Red 255 samples 3 pixels to the right, clamped at the edge
+	got := displace([4]uint8{255, 128, 0, 255}, 3)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			idx, from := (y*width+x)*4, (y*width+min(x+3, width-1))*4
+			if !bytes.Equal(got[idx:idx+4], src[from:from+4]) {
+				t.Fatalf("(%d, %d) is %v, want %v", x, y, got[idx:idx+4], src[from:from+4])
+			}
+		}
+	}
+
+	small := paramImage{data: solidImage(4, 4, [4]uint8{128, 128, 0, 255}), width: 4, height: 4}
+	if _, err := applyFilter(src, width, height, "displace", filterParams{"map": small}); err == nil {
+		t.Error("a map of the wrong size was accepted")
+	}
+}
+
+func TestMedianRemovesSaltAndPepper(t *testing.T) {
+	width, height := 24, 16
+	clean := splitImage(width, height, 12, [4]uint8{60, 60, 60, 255}, [4]uint8{190, 190, 190, 255})
+	noisy := append([]uint8(nil), clean...)
+	rng := rand.New(rand.NewSource(5))
+	for n := 0; n < 30; n++ {
+		v := uint8(0)
+		if n%2 == 0 {
+			v = 255
+		}
+		idx := rng.Intn(width*height) * 4
+		noisy[idx], noisy[idx+1], noisy[idx+2] = v, v, v
+	}
+
+	got, err := applyFilter(noisy, width, height, "median", filterParams{"size": 3.0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Isolated specks vanish and the edge stays where it was, unblurred
+	if diff := columnError(got, clean, width, height, 0, width); diff > 0.5 {
+		t.Errorf("median left a mean error of %.2f against the clean image", diff)
+	}
+	for y := 0; y < height; y++ {
+		dark, bright := (y*width+11)*4, (y*width+12)*4
+		if got[dark] != 60 || got[bright] != 190 {
+			t.Errorf("row %d: edge became %d|%d, want 60|190", y, got[dark], got[bright])
+		}
+	}
+
+	if _, err := applyFilter(noisy, width, height, "median", filterParams{"size": 4.0}); err == nil {
+		t.Error("an even size was accepted")
+	}
+}
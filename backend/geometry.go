@@ -0,0 +1,1201 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"syscall/js"
+	"time"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// applyPerspectiveWrapper wraps the applyPerspective logic for syscall/js interaction.
+// It expects imageData { width, height, data: Uint8ClampedArray } and two quads, each an
+// array of four corner points given as [x, y] pairs or { x, y } objects.
+// It returns the warped Uint8ClampedArray (same dimensions as the input) or an error object.
+func applyPerspectiveWrapper(this js.Value, args []js.Value) interface{} {
+	startTime := time.Now()
+	logDebug("applyPerspectiveWrapper called")
+
+	if len(args) < 3 {
+		return createError("Invalid number of arguments for applyPerspective: expected 3 (imageData, srcQuad, dstQuad)")
+	}
+
+	srcData, width, height, err := readImageData(args[0])
+	if err != nil {
+		return createError(err.Error())
+	}
+	srcQuad, err := readQuad(args[1])
+	if err != nil {
+		return createError("Invalid srcQuad argument: " + err.Error())
+	}
+	dstQuad, err := readQuad(args[2])
+	if err != nil {
+		return createError("Invalid dstQuad argument: " + err.Error())
+	}
+
+	resultData, err := applyPerspective(srcData, width, height, srcQuad, dstQuad)
+	if err != nil {
+		return createError(err.Error())
+	}
+
+	logInfo("applyPerspectiveWrapper completed in %v", time.Since(startTime))
+	return bytesToJS(resultData)
+}
+
+// readQuad converts a JS array of four corner points into Go coordinates.
+// Each point may be an [x, y] array or an { x, y } object.
+func readQuad(quadJS js.Value) ([4][2]float64, error) {
+	var quad [4][2]float64
+	if quadJS.Type() != js.TypeObject || quadJS.Length() != 4 {
+		return quad, errors.New("expected an array of 4 points")
+	}
+	for i := 0; i < 4; i++ {
+		p := quadJS.Index(i)
+		var xVal, yVal js.Value
+		if p.Type() != js.TypeObject {
+			return quad, fmt.Errorf("point %d is not an array or object", i)
+		}
+		if p.Get("x").Type() == js.TypeNumber {
+			xVal, yVal = p.Get("x"), p.Get("y")
+		} else {
+			xVal, yVal = p.Index(0), p.Index(1)
+		}
+		if xVal.Type() != js.TypeNumber || yVal.Type() != js.TypeNumber {
+			return quad, fmt.Errorf("point %d must have numeric x and y", i)
+		}
+		quad[i] = [2]float64{xVal.Float(), yVal.Float()}
+	}
+	return quad, nil
+}
+
+// computeHomography solves for the 3x3 projective transform H (with h33 = 1) that maps
+// each from[i] onto to[i]. The eight unknowns are found by solving an 8x8 linear system.
+func computeHomography(from, to [4][2]float64) (*mat.Dense, error) {
+	a := mat.NewDense(8, 8, nil)
+	b := mat.NewVecDense(8, nil)
+	for i := 0; i < 4; i++ {
+		x, y := from[i][0], from[i][1]
+		u, v := to[i][0], to[i][1]
+		// u = (h11*x + h12*y + h13) / (h31*x + h32*y + 1)
+		a.SetRow(2*i, []float64{x, y, 1, 0, 0, 0, -u * x, -u * y})
+		b.SetVec(2*i, u)
+		// v = (h21*x + h22*y + h23) / (h31*x + h32*y + 1)
+		a.SetRow(2*i+1, []float64{0, 0, 0, x, y, 1, -v * x, -v * y})
+		b.SetVec(2*i+1, v)
+	}
+
+	var h mat.VecDense
+	if err := h.SolveVec(a, b); err != nil {
+		return nil, fmt.Errorf("Could not compute homography (degenerate quad?): %v", err)
+	}
+	return mat.NewDense(3, 3, []float64{
+		h.AtVec(0), h.AtVec(1), h.AtVec(2),
+		h.AtVec(3), h.AtVec(4), h.AtVec(5),
+		h.AtVec(6), h.AtVec(7), 1,
+	}), nil
+}
+
+// applyPerspective warps image data so that srcQuad is mapped onto dstQuad (internal logic).
+// Each output pixel is inverse-mapped into the source and bilinearly sampled; pixels that
+// land outside the source image become fully transparent.
+func applyPerspective(srcData []uint8, width, height int, srcQuad, dstQuad [4][2]float64) ([]uint8, error) {
+	// Solve for the destination -> source mapping directly so no inversion is needed
+	h, err := computeHomography(dstQuad, srcQuad)
+	if err != nil {
+		return nil, err
+	}
+	h11, h12, h13 := h.At(0, 0), h.At(0, 1), h.At(0, 2)
+	h21, h22, h23 := h.At(1, 0), h.At(1, 1), h.At(1, 2)
+	h31, h32, h33 := h.At(2, 0), h.At(2, 1), h.At(2, 2)
+
+	logDebug("Applying perspective warp...")
+	resultData := make([]uint8, len(srcData))
+
+	parallelRows(height, func(startY, endY int) {
+		for y := startY; y < endY; y++ {
+			for x := 0; x < width; x++ {
+				fx, fy := float64(x), float64(y)
+				w := h31*fx + h32*fy + h33
+				if math.Abs(w) < 1e-12 {
+					continue // Maps to infinity, leave transparent
+				}
+				sx := (h11*fx + h12*fy + h13) / w
+				sy := (h21*fx + h22*fy + h23) / w
+
+				pixel, ok := sampleBilinear(srcData, width, height, sx, sy)
+				if !ok {
+					continue // Outside the source, leave transparent
+				}
+				idx := (y*width + x) * 4
+				for c := 0; c < 4; c++ {
+					resultData[idx+c] = uint8(clampFloat64(pixel[c]+0.5, 0, 255))
+				}
+			}
+		}
+	})
+
+	logDebug("Perspective warp complete.")
+	return resultData, nil
+}
+
+// sampleBilinear samples all four channels at the fractional pixel position (x, y),
+// where integer coordinates are pixel centers. It reports false when the position lies
+// more than half a pixel outside the image.
+func sampleBilinear(data []uint8, width, height int, x, y float64) ([4]float64, bool) {
+	var pixel [4]float64
+	if x < -0.5 || y < -0.5 || x >= float64(width)-0.5 || y >= float64(height)-0.5 {
+		return pixel, false
+	}
+
+	x0 := int(math.Floor(x))
+	y0 := int(math.Floor(y))
+	tx := x - float64(x0)
+	ty := y - float64(y0)
+	x1 := clamp(x0+1, 0, width-1)
+	y1 := clamp(y0+1, 0, height-1)
+	x0 = clamp(x0, 0, width-1)
+	y0 = clamp(y0, 0, height-1)
+
+	i00 := (y0*width + x0) * 4
+	i10 := (y0*width + x1) * 4
+	i01 := (y1*width + x0) * 4
+	i11 := (y1*width + x1) * 4
+	for c := 0; c < 4; c++ {
+		top := float64(data[i00+c])*(1-tx) + float64(data[i10+c])*tx
+		bottom := float64(data[i01+c])*(1-tx) + float64(data[i11+c])*tx
+		pixel[c] = top*(1-ty) + bottom*ty
+	}
+	return pixel, true
+}
+
+// downsampleBox shrinks image data by an integer factor, averaging each factor x factor
+// block of source pixels (partial blocks at the right/bottom edges are averaged over the
+// pixels they contain). Returns the new data and dimensions.
+func downsampleBox(srcData []uint8, width, height, factor int) ([]uint8, int, int) {
+	newWidth := (width + factor - 1) / factor
+	newHeight := (height + factor - 1) / factor
+	resultData := make([]uint8, newWidth*newHeight*4)
+
+	parallelRows(newHeight, func(startY, endY int) {
+		for y := startY; y < endY; y++ {
+			for x := 0; x < newWidth; x++ {
+				var sum [4]int
+				count := 0
+				for sy := y * factor; sy < min((y+1)*factor, height); sy++ {
+					for sx := x * factor; sx < min((x+1)*factor, width); sx++ {
+						idx := (sy*width + sx) * 4
+						for c := 0; c < 4; c++ {
+							sum[c] += int(srcData[idx+c])
+						}
+						count++
+					}
+				}
+				idx := (y*newWidth + x) * 4
+				for c := 0; c < 4; c++ {
+					resultData[idx+c] = uint8((sum[c] + count/2) / count)
+				}
+			}
+		}
+	})
+
+	return resultData, newWidth, newHeight
+}
+
+// resizeBilinear scales image data to newWidth x newHeight using bilinear interpolation
+// on all four channels. Pixel centers are aligned so that scaling preserves image extent.
+func resizeBilinear(srcData []uint8, width, height, newWidth, newHeight int) []uint8 {
+	resultData := make([]uint8, newWidth*newHeight*4)
+	scaleX := float64(width) / float64(newWidth)
+	scaleY := float64(height) / float64(newHeight)
+
+	parallelRows(newHeight, func(startY, endY int) {
+		for y := startY; y < endY; y++ {
+			sy := (float64(y)+0.5)*scaleY - 0.5
+			for x := 0; x < newWidth; x++ {
+				sx := (float64(x)+0.5)*scaleX - 0.5
+				pixel, _ := sampleBilinear(srcData, width, height, sx, sy)
+				idx := (y*newWidth + x) * 4
+				for c := 0; c < 4; c++ {
+					resultData[idx+c] = uint8(clampFloat64(pixel[c]+0.5, 0, 255))
+				}
+			}
+		}
+	})
+
+	return resultData
+}
+
+// resizeImageWrapper wraps the resizeImage logic for syscall/js interaction.
+// It expects imageData { width, height, data: Uint8ClampedArray }, the target width and
+// height (positive integers), and an optional mode string ("nearest", "bilinear", the
+// default, or "bicubic").
+// It returns { width, height, data } or an error object.
+func resizeImageWrapper(this js.Value, args []js.Value) interface{} {
+	startTime := time.Now()
+	logDebug("resizeImageWrapper called")
+
+	if len(args) < 3 {
+		return createError("Invalid number of arguments for resizeImage: expected 3 (imageData, targetWidth, targetHeight)")
+	}
+	srcData, width, height, err := readImageData(args[0])
+	if err != nil {
+		return createError(err.Error())
+	}
+	for _, v := range args[1:3] {
+		if v.Type() != js.TypeNumber || v.Float() < 1 || v.Float() != math.Trunc(v.Float()) {
+			return createError("Invalid target dimensions: expected positive integers")
+		}
+	}
+	targetWidth, targetHeight := args[1].Int(), args[2].Int()
+
+	mode := "bilinear"
+	if len(args) > 3 && !args[3].IsUndefined() {
+		if args[3].Type() != js.TypeString {
+			return createError("Invalid mode argument: expected a string")
+		}
+		mode = args[3].String()
+	}
+
+	resultData, err := resizeImage(srcData, width, height, targetWidth, targetHeight, mode)
+	if err != nil {
+		return createError(err.Error())
+	}
+
+	logInfo("resizeImageWrapper completed in %v", time.Since(startTime))
+	return imageDataToJS(resultData, targetWidth, targetHeight)
+}
+
+// resizeImage scales the image to targetWidth x targetHeight (internal logic) with the
+// given interpolation: "nearest" (resizeNearest) copies the closest source pixel, keeping
+// hard pixel-art edges; "bilinear" (resizeBilinear) blends the four surrounding pixels;
+// "bicubic" (resizeBicubic) fits a cubic through the surrounding 4x4, which is sharper. The
+// interpolating modes only look at the pixels around each output center, so downscaling by
+// more than 2x skips source pixels and can alias fine detail.
+func resizeImage(srcData []uint8, width, height, targetWidth, targetHeight int, mode string) ([]uint8, error) {
+	logDebug("Resizing %dx%d to %dx%d (%s)...", width, height, targetWidth, targetHeight, mode)
+	switch mode {
+	case "nearest":
+		return resizeNearest(srcData, width, height, targetWidth, targetHeight), nil
+	case "bilinear":
+		return resizeBilinear(srcData, width, height, targetWidth, targetHeight), nil
+	case "bicubic":
+		return resizeBicubic(srcData, width, height, targetWidth, targetHeight), nil
+	default:
+		return nil, fmt.Errorf("Invalid resize mode '%s': expected \"nearest\", \"bilinear\", or \"bicubic\"", mode)
+	}
+}
+
+// resizeNearest scales image data to newWidth x newHeight by copying, for each output
+// pixel, the source pixel its center falls in. Upscaling by an integer factor therefore
+// replicates every source pixel into an exact factor x factor block.
+func resizeNearest(srcData []uint8, width, height, newWidth, newHeight int) []uint8 {
+	resultData := make([]uint8, newWidth*newHeight*4)
+	scaleX := float64(width) / float64(newWidth)
+	scaleY := float64(height) / float64(newHeight)
+
+	parallelRows(newHeight, func(startY, endY int) {
+		for y := startY; y < endY; y++ {
+			sy := min(int((float64(y)+0.5)*scaleY), height-1)
+			for x := 0; x < newWidth; x++ {
+				sx := min(int((float64(x)+0.5)*scaleX), width-1)
+				srcIdx := (sy*width + sx) * 4
+				dstIdx := (y*newWidth + x) * 4
+				copy(resultData[dstIdx:dstIdx+4], srcData[srcIdx:srcIdx+4])
+			}
+		}
+	})
+
+	return resultData
+}
+
+// catmullRom is the Catmull-Rom cubic convolution kernel: 1 at distance 0, 0 at the other
+// integers, and negative lobes between 1 and 2 that sharpen edges.
+func catmullRom(t float64) float64 {
+	t = math.Abs(t)
+	switch {
+	case t < 1:
+		return (1.5*t-2.5)*t*t + 1
+	case t < 2:
+		return ((-0.5*t+2.5)*t-4)*t + 2
+	default:
+		return 0
+	}
+}
+
+// bicubicTaps returns, for each of n output coordinates along an axis of size srcSize, the
+// four source indices around the output center and their Catmull-Rom weights. Indices are
+// clamped into the image like the convolution code's default edge mode (clampEdges).
+func bicubicTaps(n, srcSize int) ([][4]int, [][4]float64) {
+	indices := make([][4]int, n)
+	weights := make([][4]float64, n)
+	scale := float64(srcSize) / float64(n)
+	for i := 0; i < n; i++ {
+		pos := (float64(i)+0.5)*scale - 0.5
+		base := int(math.Floor(pos))
+		t := pos - float64(base)
+		for k := 0; k < 4; k++ {
+			indices[i][k] = clampEdges.sampleIndex(base+k-1, srcSize)
+			weights[i][k] = catmullRom(t - float64(k-1))
+		}
+	}
+	return indices, weights
+}
+
+// resizeBicubic scales image data to newWidth x newHeight using Catmull-Rom bicubic
+// interpolation on all four channels, with pixel centers aligned as in resizeBilinear. The
+// kernel's negative lobes can overshoot at edges, so results are clamped to 0-255.
+func resizeBicubic(srcData []uint8, width, height, newWidth, newHeight int) []uint8 {
+	resultData := make([]uint8, newWidth*newHeight*4)
+	xIndices, xWeights := bicubicTaps(newWidth, width)
+	yIndices, yWeights := bicubicTaps(newHeight, height)
+
+	parallelRows(newHeight, func(startY, endY int) {
+		for y := startY; y < endY; y++ {
+			for x := 0; x < newWidth; x++ {
+				var pixel [4]float64
+				for j := 0; j < 4; j++ {
+					row := yIndices[y][j] * width
+					for i := 0; i < 4; i++ {
+						w := yWeights[y][j] * xWeights[x][i]
+						idx := (row + xIndices[x][i]) * 4
+						for c := 0; c < 4; c++ {
+							pixel[c] += w * float64(srcData[idx+c])
+						}
+					}
+				}
+				idx := (y*newWidth + x) * 4
+				for c := 0; c < 4; c++ {
+					resultData[idx+c] = uint8(clampFloat64(pixel[c]+0.5, 0, 255))
+				}
+			}
+		}
+	})
+
+	return resultData
+}
+
+// seamCarveWrapper wraps the seamCarve logic for syscall/js interaction.
+// It expects imageData { width, height, data: Uint8ClampedArray } and a target width.
+// It returns { width, height, data } or an error object.
+func seamCarveWrapper(this js.Value, args []js.Value) interface{} {
+	startTime := time.Now()
+	logDebug("seamCarveWrapper called")
+
+	if len(args) < 2 {
+		return createError("Invalid number of arguments for seamCarve: expected 2 (imageData, newWidth)")
+	}
+
+	srcData, width, height, err := readImageData(args[0])
+	if err != nil {
+		return createError(err.Error())
+	}
+	if args[1].Type() != js.TypeNumber {
+		return createError("Invalid newWidth argument: expected a number")
+	}
+	newWidth := args[1].Int()
+	if newWidth < 1 || newWidth > width {
+		return createError(fmt.Sprintf("Invalid newWidth %d: must be between 1 and the current width %d (only reduction is supported)", newWidth, width))
+	}
+
+	resultData := seamCarve(srcData, width, height, newWidth)
+
+	logInfo("seamCarveWrapper completed in %v", time.Since(startTime))
+	return imageDataToJS(resultData, newWidth, height)
+}
+
+// seamCarve performs content-aware width reduction (internal logic). One vertical seam is
+// removed per iteration: the energy map (Sobel gradient magnitude of luminance) is
+// recomputed, dynamic programming finds the connected top-to-bottom path of least total
+// energy, and that path's pixels are dropped from each row.
+func seamCarve(srcData []uint8, width, height, newWidth int) []uint8 {
+	logDebug("Seam carving %dx%d down to width %d...", width, height, newWidth)
+
+	data := make([]uint8, len(srcData))
+	copy(data, srcData)
+	seam := make([]int, height)
+
+	for curWidth := width; curWidth > newWidth; curWidth-- {
+		// Energy map for the current image
+		luma := lumaPlane(data, curWidth, height)
+		energy := make([]float64, curWidth*height)
+		parallelRows(height, func(startY, endY int) {
+			for y := startY; y < endY; y++ {
+				for x := 0; x < curWidth; x++ {
+					gx, gy := sobelAt(luma, curWidth, height, x, y)
+					energy[y*curWidth+x] = math.Hypot(gx, gy)
+				}
+			}
+		})
+
+		// Accumulate the minimal path cost row by row (each row depends on the previous one)
+		for y := 1; y < height; y++ {
+			for x := 0; x < curWidth; x++ {
+				best := energy[(y-1)*curWidth+x]
+				if x > 0 {
+					best = math.Min(best, energy[(y-1)*curWidth+x-1])
+				}
+				if x < curWidth-1 {
+					best = math.Min(best, energy[(y-1)*curWidth+x+1])
+				}
+				energy[y*curWidth+x] += best
+			}
+		}
+
+		// Backtrack from the cheapest bottom pixel
+		seam[height-1] = 0
+		for x := 1; x < curWidth; x++ {
+			if energy[(height-1)*curWidth+x] < energy[(height-1)*curWidth+seam[height-1]] {
+				seam[height-1] = x
+			}
+		}
+		for y := height - 2; y >= 0; y-- {
+			prev := seam[y+1]
+			seam[y] = prev
+			for x := max(prev-1, 0); x <= min(prev+1, curWidth-1); x++ {
+				if energy[y*curWidth+x] < energy[y*curWidth+seam[y]] {
+					seam[y] = x
+				}
+			}
+		}
+
+		// Remove the seam, compacting the rows into a (curWidth-1)-wide buffer
+		next := make([]uint8, (curWidth-1)*height*4)
+		parallelRows(height, func(startY, endY int) {
+			for y := startY; y < endY; y++ {
+				src := data[y*curWidth*4 : (y+1)*curWidth*4]
+				dst := next[y*(curWidth-1)*4 : (y+1)*(curWidth-1)*4]
+				cut := seam[y] * 4
+				copy(dst, src[:cut])
+				copy(dst[cut:], src[cut+4:])
+			}
+		})
+		data = next
+	}
+
+	logDebug("Seam carving complete.")
+	return data
+}
+
+// tileImageWrapper wraps the tileImage logic for syscall/js interaction.
+// It expects imageData { width, height, data: Uint8ClampedArray }, the target width and
+// height, and an optional mode string ("repeat", the default, or "mirror").
+// It returns { width, height, data } or an error object.
+func tileImageWrapper(this js.Value, args []js.Value) interface{} {
+	startTime := time.Now()
+	logDebug("tileImageWrapper called")
+
+	if len(args) < 3 {
+		return createError("Invalid number of arguments for tileImage: expected 3 (imageData, targetWidth, targetHeight)")
+	}
+
+	srcData, width, height, err := readImageData(args[0])
+	if err != nil {
+		return createError(err.Error())
+	}
+	if args[1].Type() != js.TypeNumber || args[2].Type() != js.TypeNumber || args[1].Int() <= 0 || args[2].Int() <= 0 {
+		return createError("Invalid target dimensions: expected positive numbers")
+	}
+	targetWidth, targetHeight := args[1].Int(), args[2].Int()
+
+	mirror := false
+	if len(args) > 3 && !args[3].IsUndefined() {
+		switch args[3].String() {
+		case "repeat":
+		case "mirror":
+			mirror = true
+		default:
+			return createError(fmt.Sprintf("Invalid tile mode '%s': expected \"repeat\" or \"mirror\"", args[3].String()))
+		}
+	}
+
+	resultData := tileImage(srcData, width, height, targetWidth, targetHeight, mirror)
+
+	logInfo("tileImageWrapper completed in %v", time.Since(startTime))
+	return imageDataToJS(resultData, targetWidth, targetHeight)
+}
+
+// tileImage repeats the source image to fill targetWidth x targetHeight (internal logic).
+// In mirror mode every other tile is flipped horizontally and/or vertically so that tile
+// borders meet seamlessly.
+func tileImage(srcData []uint8, width, height, targetWidth, targetHeight int, mirror bool) []uint8 {
+	resultData := make([]uint8, targetWidth*targetHeight*4)
+
+	// sourceCoord maps an output coordinate to the source coordinate along one axis
+	sourceCoord := func(v, size int) int {
+		tile, offset := v/size, v%size
+		if mirror && tile%2 == 1 {
+			return size - 1 - offset
+		}
+		return offset
+	}
+
+	parallelRows(targetHeight, func(startY, endY int) {
+		for y := startY; y < endY; y++ {
+			sy := sourceCoord(y, height)
+			for x := 0; x < targetWidth; x++ {
+				sx := sourceCoord(x, width)
+				srcIdx := (sy*width + sx) * 4
+				dstIdx := (y*targetWidth + x) * 4
+				copy(resultData[dstIdx:dstIdx+4], srcData[srcIdx:srcIdx+4])
+			}
+		}
+	})
+
+	return resultData
+}
+
+// autoCropWrapper wraps the autoCrop logic for syscall/js interaction.
+// It expects imageData { width, height, data: Uint8ClampedArray } and a tolerance (0-255).
+// It returns { width, height, data, crop: { x, y, width, height } } or an error object.
+func autoCropWrapper(this js.Value, args []js.Value) interface{} {
+	startTime := time.Now()
+	logDebug("autoCropWrapper called")
+
+	if len(args) < 2 {
+		return createError("Invalid number of arguments for autoCrop: expected 2 (imageData, tolerance)")
+	}
+	srcData, width, height, err := readImageData(args[0])
+	if err != nil {
+		return createError(err.Error())
+	}
+	if args[1].Type() != js.TypeNumber || args[1].Int() < 0 || args[1].Int() > 255 {
+		return createError("Invalid tolerance argument: expected a number between 0 and 255")
+	}
+
+	x, y, cropWidth, cropHeight, err := findCropRect(srcData, width, height, args[1].Int())
+	if err != nil {
+		return createError(err.Error())
+	}
+	resultData := cropImage(srcData, width, x, y, cropWidth, cropHeight)
+
+	result := imageDataToJS(resultData, cropWidth, cropHeight)
+	rect := js.Global().Get("Object").New()
+	rect.Set("x", x)
+	rect.Set("y", y)
+	rect.Set("width", cropWidth)
+	rect.Set("height", cropHeight)
+	result.Set("crop", rect)
+
+	logInfo("autoCropWrapper completed in %v", time.Since(startTime))
+	return result
+}
+
+// findCropRect locates the content rectangle inside uniform borders (internal logic for
+// autoCrop). The top-left pixel is taken as the border color; rows and columns are
+// scanned inward from each edge and trimmed while every pixel in them is within
+// `tolerance` of that color on all four channels. An entirely uniform image is an error.
+func findCropRect(srcData []uint8, width, height, tolerance int) (int, int, int, int, error) {
+	border := srcData[0:4]
+	isBorder := func(x, y int) bool {
+		idx := (y*width + x) * 4
+		for c := 0; c < 4; c++ {
+			d := int(srcData[idx+c]) - int(border[c])
+			if d < -tolerance || d > tolerance {
+				return false
+			}
+		}
+		return true
+	}
+	rowIsBorder := func(y, x0, x1 int) bool {
+		for x := x0; x < x1; x++ {
+			if !isBorder(x, y) {
+				return false
+			}
+		}
+		return true
+	}
+	colIsBorder := func(x, y0, y1 int) bool {
+		for y := y0; y < y1; y++ {
+			if !isBorder(x, y) {
+				return false
+			}
+		}
+		return true
+	}
+
+	top, bottom := 0, height
+	for top < bottom && rowIsBorder(top, 0, width) {
+		top++
+	}
+	if top == bottom {
+		return 0, 0, 0, 0, fmt.Errorf("Cannot auto-crop: the whole image is uniform within tolerance %d", tolerance)
+	}
+	for rowIsBorder(bottom-1, 0, width) {
+		bottom--
+	}
+	left, right := 0, width
+	for colIsBorder(left, top, bottom) {
+		left++
+	}
+	for colIsBorder(right-1, top, bottom) {
+		right--
+	}
+	return left, top, right - left, bottom - top, nil
+}
+
+// cropImage copies the rectangle (x, y, cropWidth, cropHeight) out of an image that is
+// `width` pixels wide. The rectangle must lie inside the image.
+func cropImage(srcData []uint8, width, x, y, cropWidth, cropHeight int) []uint8 {
+	resultData := make([]uint8, cropWidth*cropHeight*4)
+	for row := 0; row < cropHeight; row++ {
+		src := ((y+row)*width + x) * 4
+		copy(resultData[row*cropWidth*4:(row+1)*cropWidth*4], srcData[src:src+cropWidth*4])
+	}
+	return resultData
+}
+
+// pasteImage copies a srcWidth x srcHeight image into dst (an image `dstWidth` pixels wide)
+// with its top-left corner at (x, y). The pasted rectangle must lie inside dst.
+func pasteImage(dst []uint8, dstWidth int, src []uint8, srcWidth, srcHeight, x, y int) {
+	for row := 0; row < srcHeight; row++ {
+		dstIdx := ((y+row)*dstWidth + x) * 4
+		copy(dst[dstIdx:dstIdx+srcWidth*4], src[row*srcWidth*4:(row+1)*srcWidth*4])
+	}
+}
+
+// makeComparisonWrapper wraps the makeComparison logic for syscall/js interaction.
+// It expects two imageData objects { width, height, data: Uint8ClampedArray }, the gap in
+// pixels between them, and an optional [r, g, b, a] background color (default opaque white).
+// It returns { width, height, data } or an error object.
+func makeComparisonWrapper(this js.Value, args []js.Value) interface{} {
+	startTime := time.Now()
+	logDebug("makeComparisonWrapper called")
+
+	if len(args) < 3 {
+		return createError("Invalid number of arguments for makeComparison: expected 3 (imageDataA, imageDataB, labelGap)")
+	}
+	dataA, widthA, heightA, err := readImageData(args[0])
+	if err != nil {
+		return createError(err.Error())
+	}
+	dataB, widthB, heightB, err := readImageData(args[1])
+	if err != nil {
+		return createError(err.Error())
+	}
+	if args[2].Type() != js.TypeNumber || args[2].Int() < 0 {
+		return createError("Invalid labelGap argument: expected a non-negative number")
+	}
+	background := [4]uint8{255, 255, 255, 255}
+	if len(args) > 3 && !args[3].IsUndefined() {
+		if background, err = readColor(args[3]); err != nil {
+			return createError(err.Error())
+		}
+	}
+
+	resultData, width, height := makeComparison(dataA, widthA, heightA, dataB, widthB, heightB, args[2].Int(), background)
+
+	logInfo("makeComparisonWrapper completed in %v", time.Since(startTime))
+	return imageDataToJS(resultData, width, height)
+}
+
+// makeComparison lays images A and B out side by side, separated by a gap, for before/after
+// reports (internal logic). Both are aligned to the top; the gap and the area below the
+// shorter image are filled with the background color. The result is
+// (widthA + gap + widthB) x max(heightA, heightB).
+func makeComparison(dataA []uint8, widthA, heightA int, dataB []uint8, widthB, heightB, gap int, background [4]uint8) ([]uint8, int, int) {
+	width := widthA + gap + widthB
+	height := max(heightA, heightB)
+
+	resultData := make([]uint8, width*height*4)
+	for i := 0; i < len(resultData); i += 4 {
+		copy(resultData[i:i+4], background[:])
+	}
+	pasteImage(resultData, width, dataA, widthA, heightA, 0, 0)
+	pasteImage(resultData, width, dataB, widthB, heightB, widthA+gap, 0)
+	return resultData, width, height
+}
+
+// rotateImageWrapper wraps the rotateImage logic for syscall/js interaction.
+// It expects imageData { width, height, data: Uint8ClampedArray } and an angle in degrees
+// (clockwise) that is a multiple of 90; negative angles rotate counterclockwise.
+// It returns { width, height, data } or an error object.
+func rotateImageWrapper(this js.Value, args []js.Value) interface{} {
+	startTime := time.Now()
+	logDebug("rotateImageWrapper called")
+
+	if len(args) < 2 {
+		return createError("Invalid number of arguments for rotateImage: expected 2 (imageData, degrees)")
+	}
+	srcData, width, height, err := readImageData(args[0])
+	if err != nil {
+		return createError(err.Error())
+	}
+	if args[1].Type() != js.TypeNumber || math.Mod(args[1].Float(), 90) != 0 {
+		return createError("Invalid degrees argument: expected a multiple of 90 (use rotateArbitrary for other angles)")
+	}
+	// Normalize to a number of clockwise quarter turns in [0, 4)
+	quarterTurns := (int(args[1].Float()/90)%4 + 4) % 4
+
+	resultData, newWidth, newHeight := rotateImage(srcData, width, height, quarterTurns)
+
+	logInfo("rotateImageWrapper completed in %v", time.Since(startTime))
+	return imageDataToJS(resultData, newWidth, newHeight)
+}
+
+// rotateImage rotates the image clockwise by quarterTurns * 90 degrees (internal logic).
+// This is an exact permutation of pixels: each output pixel copies the source pixel the
+// rotation maps onto it, so no interpolation is needed. One and three quarter turns swap
+// the width and height. Returns the new data and dimensions.
+func rotateImage(srcData []uint8, width, height, quarterTurns int) ([]uint8, int, int) {
+	newWidth, newHeight := width, height
+	if quarterTurns%2 == 1 {
+		newWidth, newHeight = height, width
+	}
+	logDebug("Rotating %dx%d by %d quarter turns into %dx%d", width, height, quarterTurns, newWidth, newHeight)
+
+	// source returns the source coordinates of output pixel (x, y)
+	source := func(x, y int) (int, int) {
+		switch quarterTurns {
+		case 1:
+			return y, height - 1 - x
+		case 2:
+			return width - 1 - x, height - 1 - y
+		case 3:
+			return width - 1 - y, x
+		default:
+			return x, y
+		}
+	}
+
+	resultData := make([]uint8, len(srcData))
+	parallelRows(newHeight, func(startY, endY int) {
+		for y := startY; y < endY; y++ {
+			for x := 0; x < newWidth; x++ {
+				sx, sy := source(x, y)
+				srcIdx := (sy*width + sx) * 4
+				dstIdx := (y*newWidth + x) * 4
+				copy(resultData[dstIdx:dstIdx+4], srcData[srcIdx:srcIdx+4])
+			}
+		}
+	})
+	return resultData, newWidth, newHeight
+}
+
+// flipImageWrapper wraps the flipImage logic for syscall/js interaction.
+// It expects imageData { width, height, data: Uint8ClampedArray } and a mode string
+// ("horizontal" or "vertical").
+// It returns the processed Uint8ClampedArray or an error object.
+func flipImageWrapper(this js.Value, args []js.Value) interface{} {
+	startTime := time.Now()
+	logDebug("flipImageWrapper called")
+
+	if len(args) < 2 {
+		return createError("Invalid number of arguments for flipImage: expected 2 (imageData, mode)")
+	}
+	srcData, width, height, err := readImageData(args[0])
+	if err != nil {
+		return createError(err.Error())
+	}
+	mode := ""
+	if args[1].Type() == js.TypeString {
+		mode = args[1].String()
+	}
+	var horizontal bool
+	switch mode {
+	case "horizontal":
+		horizontal = true
+	case "vertical":
+	default:
+		return createError("Invalid mode argument: expected \"horizontal\" or \"vertical\"")
+	}
+
+	resultData := flipImage(srcData, width, height, horizontal)
+
+	logInfo("flipImageWrapper completed in %v", time.Since(startTime))
+	return bytesToJS(resultData)
+}
+
+// flipImage mirrors the image (internal logic): horizontally reverses the pixels within
+// each row, otherwise the order of the rows is reversed. Dimensions are unchanged.
+func flipImage(srcData []uint8, width, height int, horizontal bool) []uint8 {
+	logDebug("Flipping %dx%d image (horizontal: %v)...", width, height, horizontal)
+	resultData := make([]uint8, len(srcData))
+	rowBytes := width * 4
+	parallelRows(height, func(startY, endY int) {
+		for y := startY; y < endY; y++ {
+			dst := resultData[y*rowBytes : (y+1)*rowBytes]
+			if !horizontal {
+				copy(dst, srcData[(height-1-y)*rowBytes:(height-y)*rowBytes])
+				continue
+			}
+			src := srcData[y*rowBytes : (y+1)*rowBytes]
+			for x := 0; x < width; x++ {
+				copy(dst[x*4:x*4+4], src[(width-1-x)*4:(width-x)*4])
+			}
+		}
+	})
+	return resultData
+}
+
+// rotateArbitraryWrapper wraps the rotateArbitrary logic for syscall/js interaction.
+// It expects imageData { width, height, data: Uint8ClampedArray }, an angle in degrees
+// (clockwise), an optional expand flag (default false) that grows the canvas to the rotated
+// image's bounding box instead of cropping to the original size, and an optional
+// [r, g, b, a] background color for the exposed corners (default transparent).
+// It returns { width, height, data } or an error object.
+func rotateArbitraryWrapper(this js.Value, args []js.Value) interface{} {
+	startTime := time.Now()
+	logDebug("rotateArbitraryWrapper called")
+
+	if len(args) < 2 {
+		return createError("Invalid number of arguments for rotateArbitrary: expected 2 (imageData, degrees)")
+	}
+	srcData, width, height, err := readImageData(args[0])
+	if err != nil {
+		return createError(err.Error())
+	}
+	if args[1].Type() != js.TypeNumber || math.IsNaN(args[1].Float()) || math.IsInf(args[1].Float(), 0) {
+		return createError("Invalid degrees argument: expected a finite number")
+	}
+	expand := len(args) > 2 && args[2].Truthy()
+	var background [4]uint8
+	if len(args) > 3 && !args[3].IsUndefined() && !args[3].IsNull() {
+		if background, err = readColor(args[3]); err != nil {
+			return createError(err.Error())
+		}
+	}
+
+	resultData, newWidth, newHeight := rotateArbitrary(srcData, width, height, args[1].Float(), expand, background)
+
+	logInfo("rotateArbitraryWrapper completed in %v", time.Since(startTime))
+	return imageDataToJS(resultData, newWidth, newHeight)
+}
+
+// rotateArbitrary rotates the image clockwise by `degrees` about its center (internal
+// logic). Each output pixel is inverse-rotated into the source and bilinearly sampled;
+// pixels that fall outside the source take the background color (transparent black, alpha
+// 0, when it is the zero value). With expand the canvas grows to the rotated image's
+// bounding box, otherwise it keeps the original size and the corners are cropped. Returns
+// the new data and dimensions.
+func rotateArbitrary(srcData []uint8, width, height int, degrees float64, expand bool, background [4]uint8) ([]uint8, int, int) {
+	theta := degrees * math.Pi / 180
+	cos, sin := math.Cos(theta), math.Sin(theta)
+
+	newWidth, newHeight := width, height
+	if expand {
+		// Round away floating-point noise first so that e.g. 360° does not grow by a pixel
+		fit := func(v float64) int {
+			return int(math.Ceil(math.Round(v*1e6) / 1e6))
+		}
+		newWidth = fit(float64(width)*math.Abs(cos) + float64(height)*math.Abs(sin))
+		newHeight = fit(float64(width)*math.Abs(sin) + float64(height)*math.Abs(cos))
+	}
+	logDebug("Rotating %dx%d by %.2f degrees into %dx%d", width, height, degrees, newWidth, newHeight)
+
+	srcCX, srcCY := float64(width-1)/2, float64(height-1)/2
+	dstCX, dstCY := float64(newWidth-1)/2, float64(newHeight-1)/2
+	resultData := make([]uint8, newWidth*newHeight*4)
+
+	parallelRows(newHeight, func(startY, endY int) {
+		for y := startY; y < endY; y++ {
+			dy := float64(y) - dstCY
+			for x := 0; x < newWidth; x++ {
+				dx := float64(x) - dstCX
+				// Inverse of the clockwise (y-down) rotation
+				sx := cos*dx + sin*dy + srcCX
+				sy := -sin*dx + cos*dy + srcCY
+
+				pixel, ok := sampleBilinear(srcData, width, height, sx, sy)
+				idx := (y*newWidth + x) * 4
+				if !ok {
+					copy(resultData[idx:idx+4], background[:]) // Outside the source
+					continue
+				}
+				for c := 0; c < 4; c++ {
+					resultData[idx+c] = uint8(clampFloat64(pixel[c]+0.5, 0, 255))
+				}
+			}
+		}
+	})
+	return resultData, newWidth, newHeight
+}
+
+// contactImage is one input image of makeContactSheet.
+type contactImage struct {
+	data          []uint8
+	width, height int
+}
+
+// makeContactSheetWrapper wraps the makeContactSheet logic for syscall/js interaction.
+// It expects an array of imageData objects { width, height, data: Uint8ClampedArray }, the
+// thumbnail width, the number of columns, the gap in pixels, and an optional [r, g, b, a]
+// background color (default opaque white).
+// It returns { width, height, data } or an error object.
+func makeContactSheetWrapper(this js.Value, args []js.Value) interface{} {
+	startTime := time.Now()
+	logDebug("makeContactSheetWrapper called")
+
+	if len(args) < 4 {
+		return createError("Invalid number of arguments for makeContactSheet: expected 4 (imageDataArray, thumbWidth, columns, gap)")
+	}
+	if args[0].Type() != js.TypeObject || args[0].Length() == 0 {
+		return createError("Invalid imageDataArray argument: expected a non-empty array of imageData objects")
+	}
+	images := make([]contactImage, args[0].Length())
+	for i := range images {
+		data, width, height, err := readImageData(args[0].Index(i))
+		if err != nil {
+			return createError(fmt.Sprintf("Image %d: %s", i, err.Error()))
+		}
+		images[i] = contactImage{data, width, height}
+	}
+	for i, name := range []string{"thumbWidth", "columns"} {
+		if args[1+i].Type() != js.TypeNumber || args[1+i].Int() < 1 {
+			return createError(fmt.Sprintf("Invalid %s argument: expected a positive number", name))
+		}
+	}
+	if args[3].Type() != js.TypeNumber || args[3].Int() < 0 {
+		return createError("Invalid gap argument: expected a non-negative number")
+	}
+	background := [4]uint8{255, 255, 255, 255}
+	if len(args) > 4 && !args[4].IsUndefined() {
+		var err error
+		if background, err = readColor(args[4]); err != nil {
+			return createError(err.Error())
+		}
+	}
+
+	resultData, width, height := makeContactSheet(images, args[1].Int(), args[2].Int(), args[3].Int(), background)
+
+	logInfo("makeContactSheetWrapper completed in %v", time.Since(startTime))
+	return imageDataToJS(resultData, width, height)
+}
+
+// makeThumbnail scales an image to thumbWidth wide, keeping its aspect ratio. Large
+// reductions are first box-averaged by the largest integer factor that stays above the
+// target, so the final bilinear pass does not alias.
+func makeThumbnail(img contactImage, thumbWidth int) ([]uint8, int) {
+	thumbHeight := max(1, int(math.Round(float64(img.height)*float64(thumbWidth)/float64(img.width))))
+	data, width, height := img.data, img.width, img.height
+	if factor := min(width/thumbWidth, height/thumbHeight); factor >= 2 {
+		data, width, height = downsampleBox(data, width, height, factor)
+	}
+	return resizeBilinear(data, width, height, thumbWidth, thumbHeight), thumbHeight
+}
+
+// makeContactSheet lays thumbnails of the images out in a grid (internal logic). Every image
+// is scaled to thumbWidth wide and placed left to right, `columns` per row, with `gap` pixels
+// between cells. Each row is as tall as its tallest thumbnail and thumbnails are top-aligned;
+// a partial last row is left-aligned. Uncovered areas are filled with the background color.
+// Returns the sheet and its dimensions.
+func makeContactSheet(images []contactImage, thumbWidth, columns, gap int, background [4]uint8) ([]uint8, int, int) {
+	columns = min(columns, len(images))
+	thumbs := make([][]uint8, len(images))
+	thumbHeights := make([]int, len(images))
+	for i, img := range images {
+		thumbs[i], thumbHeights[i] = makeThumbnail(img, thumbWidth)
+	}
+
+	numRows := (len(images) + columns - 1) / columns
+	rowTops := make([]int, numRows)
+	height := 0
+	for row := 0; row < numRows; row++ {
+		if row > 0 {
+			height += gap
+		}
+		rowTops[row] = height
+		rowHeight := 0
+		for i := row * columns; i < min((row+1)*columns, len(images)); i++ {
+			rowHeight = max(rowHeight, thumbHeights[i])
+		}
+		height += rowHeight
+	}
+	width := columns*thumbWidth + (columns-1)*gap
+	logDebug("Contact sheet: %d images in %d columns, %dx%d", len(images), columns, width, height)
+
+	resultData := make([]uint8, width*height*4)
+	for i := 0; i < len(resultData); i += 4 {
+		copy(resultData[i:i+4], background[:])
+	}
+	for i, thumb := range thumbs {
+		x := (i % columns) * (thumbWidth + gap)
+		pasteImage(resultData, width, thumb, thumbWidth, thumbHeights[i], x, rowTops[i/columns])
+	}
+	return resultData, width, height
+}
+
+// extractPatchesWrapper wraps the extractPatches logic for syscall/js interaction.
+// It expects imageData { width, height, data: Uint8ClampedArray }, the patch size, the
+// stride, and an optional edge mode string ("clamp", the default, or "drop").
+// It returns an array of { x, y, width, height, data } patches in row-major grid order, or
+// an error object.
+func extractPatchesWrapper(this js.Value, args []js.Value) interface{} {
+	startTime := time.Now()
+	logDebug("extractPatchesWrapper called")
+
+	if len(args) < 3 {
+		return createError("Invalid number of arguments for extractPatches: expected 3 (imageData, patchSize, stride)")
+	}
+	srcData, width, height, err := readImageData(args[0])
+	if err != nil {
+		return createError(err.Error())
+	}
+	if args[1].Type() != js.TypeNumber || args[1].Int() <= 0 || args[1].Int() > min(width, height) {
+		return createError(fmt.Sprintf("Invalid patchSize argument: expected a number between 1 and %d", min(width, height)))
+	}
+	if args[2].Type() != js.TypeNumber || args[2].Int() <= 0 {
+		return createError("Invalid stride argument: expected a positive number")
+	}
+	patchSize, stride := args[1].Int(), args[2].Int()
+
+	clampLast := true
+	if len(args) > 3 && !args[3].IsUndefined() {
+		switch args[3].String() {
+		case "clamp":
+		case "drop":
+			clampLast = false
+		default:
+			return createError(fmt.Sprintf("Invalid edge mode '%s': expected \"clamp\" or \"drop\"", args[3].String()))
+		}
+	}
+
+	origins := patchOrigins(width, height, patchSize, stride, clampLast)
+	patches := js.Global().Get("Array").New(len(origins))
+	for i, o := range origins {
+		patch := imageDataToJS(cropImage(srcData, width, o[0], o[1], patchSize, patchSize), patchSize, patchSize)
+		patch.Set("x", o[0])
+		patch.Set("y", o[1])
+		patches.SetIndex(i, patch)
+	}
+
+	logInfo("extractPatchesWrapper completed in %v (%d patches)", time.Since(startTime), len(origins))
+	return patches
+}
+
+// patchOrigins returns the top-left corners of the patchSize x patchSize patches that
+// extractPatches takes from a width x height image, in row-major order (internal logic).
+// Patches start every `stride` pixels along each axis. Where the grid does not end exactly
+// at the image edge, clampLast adds a final patch aligned to the edge (overlapping its
+// neighbor more than the stride would), so every pixel is covered; otherwise the partial
+// patch is dropped. patchSize must not exceed either dimension.
+func patchOrigins(width, height, patchSize, stride int, clampLast bool) [][2]int {
+	axis := func(size int) []int {
+		var starts []int
+		for v := 0; v+patchSize <= size; v += stride {
+			starts = append(starts, v)
+		}
+		if last := size - patchSize; clampLast && starts[len(starts)-1] != last {
+			starts = append(starts, last)
+		}
+		return starts
+	}
+
+	xs, ys := axis(width), axis(height)
+	origins := make([][2]int, 0, len(xs)*len(ys))
+	for _, y := range ys {
+		for _, x := range xs {
+			origins = append(origins, [2]int{x, y})
+		}
+	}
+	return origins
+}
+
+// warpPoint moves the image content at (fromX, fromY) to (toX, toY), affecting pixels
+// within radius of the destination.
+type warpPoint struct {
+	fromX, fromY, toX, toY, radius float64
+}
+
+// applyWarpPointsWrapper wraps the applyWarpPoints logic for syscall/js interaction.
+// It expects imageData { width, height, data: Uint8ClampedArray } and an array of points
+// { fromX, fromY, toX, toY, radius } in pixel coordinates.
+// It returns the warped Uint8ClampedArray or an error object.
+func applyWarpPointsWrapper(this js.Value, args []js.Value) interface{} {
+	startTime := time.Now()
+	logDebug("applyWarpPointsWrapper called")
+
+	if len(args) < 2 {
+		return createError("Invalid number of arguments for applyWarpPoints: expected 2 (imageData, points)")
+	}
+	srcData, width, height, err := readImageData(args[0])
+	if err != nil {
+		return createError(err.Error())
+	}
+	pointsJS := args[1]
+	if pointsJS.Type() != js.TypeObject || pointsJS.Get("length").Type() != js.TypeNumber {
+		return createError("Invalid points argument: expected an array of { fromX, fromY, toX, toY, radius }")
+	}
+	points := make([]warpPoint, pointsJS.Length())
+	for i := range points {
+		p := pointsJS.Index(i)
+		if p.Type() != js.TypeObject {
+			return createError(fmt.Sprintf("Invalid point %d: expected an object { fromX, fromY, toX, toY, radius }", i))
+		}
+		var values [5]float64
+		for j, key := range []string{"fromX", "fromY", "toX", "toY", "radius"} {
+			v := p.Get(key)
+			if v.Type() != js.TypeNumber {
+				return createError(fmt.Sprintf("Invalid point %d: %s must be a number", i, key))
+			}
+			values[j] = v.Float()
+		}
+		if values[4] <= 0 {
+			return createError(fmt.Sprintf("Invalid point %d: radius must be positive", i))
+		}
+		points[i] = warpPoint{values[0], values[1], values[2], values[3], values[4]}
+	}
+
+	resultData := applyWarpPoints(srcData, width, height, points)
+
+	logInfo("applyWarpPointsWrapper completed in %v", time.Since(startTime))
+	return bytesToJS(resultData)
+}
+
+// applyWarpPoints warps the image so each point's source location moves to its
+// destination (internal logic). Every output pixel is inverse-mapped: each point within
+// `radius` of the pixel contributes its displacement (from - to), weighted by the smooth
+// falloff (1 - (d/radius)²)², so the destination itself samples exactly the source location
+// and the effect fades to nothing at the radius. Where points overlap and their weights
+// sum to more than 1, the displacement is their weighted average instead. The source is
+// sampled bilinearly, clamped to the image bounds. No points leaves the image unchanged.
+// Keep each displacement well below its radius; a point moved further than about half its
+// radius folds the content near the edge of its area over itself.
+func applyWarpPoints(srcData []uint8, width, height int, points []warpPoint) []uint8 {
+	logDebug("Warping image with %d points", len(points))
+	resultData := make([]uint8, len(srcData))
+	if len(points) == 0 {
+		copy(resultData, srcData)
+		return resultData
+	}
+
+	maxX, maxY := float64(width-1), float64(height-1)
+	parallelRows(height, func(startY, endY int) {
+		for y := startY; y < endY; y++ {
+			for x := 0; x < width; x++ {
+				var dx, dy, totalWeight float64
+				for _, p := range points {
+					ex, ey := float64(x)-p.toX, float64(y)-p.toY
+					t := (ex*ex + ey*ey) / (p.radius * p.radius)
+					if t >= 1 {
+						continue
+					}
+					w := (1 - t) * (1 - t)
+					dx += w * (p.fromX - p.toX)
+					dy += w * (p.fromY - p.toY)
+					totalWeight += w
+				}
+				if totalWeight > 1 {
+					dx, dy = dx/totalWeight, dy/totalWeight
+				}
+
+				idx := (y*width + x) * 4
+				if totalWeight == 0 {
+					copy(resultData[idx:idx+4], srcData[idx:idx+4])
+					continue
+				}
+				sx := clampFloat64(float64(x)+dx, 0, maxX)
+				sy := clampFloat64(float64(y)+dy, 0, maxY)
+				pixel, _ := sampleBilinear(srcData, width, height, sx, sy)
+				for c := 0; c < 4; c++ {
+					resultData[idx+c] = uint8(clampFloat64(pixel[c]+0.5, 0, 255))
+				}
+			}
+		}
+	})
+	return resultData
+}
@@ -0,0 +1,458 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"syscall/js"
+	"testing"
+)
+
+func TestRotateArbitraryBoundingBox(t *testing.T) {
+	width, height := 40, 20
+	src := randomImage(width, height, 1)
+	for _, tc := range []struct {
+		degrees       float64
+		width, height int
+	}{
+		{0, 40, 20},
+		{90, 20, 40},
+		{180, 40, 20},
+		{360, 40, 20},
+		{45, 43, 43}, // (40 + 20) / √2 = 42.4
+	} {
+		_, w, h := rotateArbitrary(src, width, height, tc.degrees, true, [4]uint8{})
+		if w != tc.width || h != tc.height {
+			t.Errorf("%v°: got %dx%d, want %dx%d", tc.degrees, w, h, tc.width, tc.height)
+		}
+	}
+}
+
+func TestRotateArbitraryFullTurn(t *testing.T) {
+	width, height := 23, 17
+	src := randomImage(width, height, 2)
+	got, w, h := rotateArbitrary(src, width, height, 360, true, [4]uint8{})
+	if w != width || h != height {
+		t.Fatalf("got %dx%d, want %dx%d", w, h, width, height)
+	}
+	if diff := maxAbsDiff(got, src); diff > 1 {
+		t.Errorf("360° rotation differs from the input by %d", diff)
+	}
+}
+
+func TestRotateArbitraryWrapperArguments(t *testing.T) {
+	width, height := 20, 10
+	img := imageDataToJS(solidImage(width, height, [4]uint8{0, 0, 255, 255}), width, height)
+	red := js.ValueOf([]interface{}{255, 0, 0})
+	rotate := func(args ...interface{}) ([]uint8, int, int) {
+		t.Helper()
+		jsArgs := []js.Value{img, js.ValueOf(30)}
+		for _, a := range args {
+			jsArgs = append(jsArgs, js.ValueOf(a))
+		}
+		data, w, h, err := readImageData(rotateArbitraryWrapper(js.Undefined(), jsArgs).(js.Value))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data, w, h
+	}
+
+	// Without expand the canvas keeps its size and the exposed corners are transparent
+	data, w, h := rotate()
+	if w != width || h != height {
+		t.Errorf("default: got %dx%d, want %dx%d", w, h, width, height)
+	}
+	if data[3] != 0 {
+		t.Errorf("default background alpha is %d, want 0", data[3])
+	}
+
+	data, w, h = rotate(true, red)
+	if w <= width || h <= height {
+		t.Errorf("expand: got %dx%d, want the bounding box of the rotated %dx%d image", w, h, width, height)
+	}
+	if corner := [4]uint8(data[:4]); corner != [4]uint8{255, 0, 0, 255} {
+		t.Errorf("exposed corner is %v, want the opaque red background", corner)
+	}
+
+	data, w, h = rotate(false, red)
+	if w != width || h != height || [4]uint8(data[:4]) != [4]uint8{255, 0, 0, 255} {
+		t.Errorf("crop with background: got %dx%d with corner %v", w, h, data[:4])
+	}
+}
+
+func TestApplyPerspective(t *testing.T) {
+	width, height := 30, 20
+	src := randomImage(width, height, 3)
+	corners := [4][2]float64{{0, 0}, {29, 0}, {29, 19}, {0, 19}}
+
+	got, err := applyPerspective(src, width, height, corners, corners)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := maxAbsDiff(got, src); diff != 0 {
+		t.Errorf("identity quad changed the image by %d", diff)
+	}
+
+	// Moving every corner 3 pixels right shifts the image and exposes a transparent strip
+	var shifted [4][2]float64
+	for i, p := range corners {
+		shifted[i] = [2]float64{p[0] + 3, p[1]}
+	}
+	if got, err = applyPerspective(src, width, height, corners, shifted); err != nil {
+		t.Fatal(err)
+	}
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			idx := (y*width + x) * 4
+			if x < 3 {
+				if got[idx+3] != 0 {
+					t.Fatalf("(%d, %d) alpha %d, want transparent", x, y, got[idx+3])
+				}
+				continue
+			}
+			if diff := maxAbsDiff(got[idx:idx+4], src[idx-12:idx-8]); diff > 1 {
+				t.Fatalf("(%d, %d) differs from source (%d, %d) by %d", x, y, x-3, y, diff)
+			}
+		}
+	}
+
+	degenerate := [4][2]float64{{0, 0}, {0, 0}, {0, 0}, {0, 0}}
+	if _, err := applyPerspective(src, width, height, degenerate, corners); err == nil {
+		t.Error("degenerate quad did not return an error")
+	}
+}
+
+func TestSeamCarve(t *testing.T) {
+	// A red stripe on a flat background: the cheapest seams run through the flat areas
+	width, height := 20, 10
+	red := [4]uint8{220, 20, 20, 255}
+	src := splitImage(width, height, 0, red, [4]uint8{90, 90, 90, 255})
+	for y := 0; y < height; y++ {
+		for x := 9; x < 11; x++ {
+			copy(src[(y*width+x)*4:], red[:])
+		}
+	}
+
+	newWidth := 14
+	got := seamCarve(src, width, height, newWidth)
+	if len(got) != newWidth*height*4 {
+		t.Fatalf("got %d bytes, want %d for %dx%d", len(got), newWidth*height*4, newWidth, height)
+	}
+	for y := 0; y < height; y++ {
+		reds := 0
+		for x := 0; x < newWidth; x++ {
+			if [4]uint8(got[(y*newWidth+x)*4:]) == red {
+				reds++
+			}
+		}
+		if reds != 2 {
+			t.Errorf("row %d keeps %d stripe pixels, want 2", y, reds)
+		}
+	}
+
+	result := seamCarveWrapper(js.Undefined(), []js.Value{imageDataToJS(src, width, height), js.ValueOf(width + 1)}).(js.Value)
+	if result.Get("error").Type() != js.TypeString {
+		t.Error("widening did not return an error")
+	}
+}
+
+func TestTileImage(t *testing.T) {
+	// 2x2 source with pixels A B / C D, identified by their red value
+	src := make([]uint8, 2*2*4)
+	for i, v := range []uint8{'A', 'B', 'C', 'D'} {
+		src[i*4], src[i*4+3] = v, 255
+	}
+	for _, tc := range []struct {
+		mirror bool
+		want   string
+	}{
+		{false, "ABAB" + "CDCD" + "ABAB" + "CDCD"},
+		{true, "ABBA" + "CDDC" + "CDDC" + "ABBA"},
+	} {
+		got := tileImage(src, 2, 2, 4, 4, tc.mirror)
+		pattern := make([]byte, 0, 16)
+		for i := 0; i < len(got); i += 4 {
+			pattern = append(pattern, got[i])
+		}
+		if string(pattern) != tc.want {
+			t.Errorf("mirror %v: got %s, want %s", tc.mirror, pattern, tc.want)
+		}
+	}
+}
+
+func TestAutoCrop(t *testing.T) {
+	// Random content at (3, 2) size 8x5 inside a 16x10 near-white frame
+	width, height := 16, 10
+	src := solidImage(width, height, [4]uint8{255, 255, 255, 255})
+	content := randomImage(8, 5, 1)
+	for i := 0; i < len(content); i += 4 {
+		content[i] = uint8(min(int(content[i]), 200)) // Never mistaken for the frame
+	}
+	pasteImage(src, width, content, 8, 5, 3, 2)
+	src[(9*width+15)*4] = 250 // Frame noise within tolerance
+
+	x, y, w, h, err := findCropRect(src, width, height, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if x != 3 || y != 2 || w != 8 || h != 5 {
+		t.Fatalf("crop rectangle (%d, %d, %d, %d), want (3, 2, 8, 5)", x, y, w, h)
+	}
+	if got := cropImage(src, width, x, y, w, h); !bytes.Equal(got, content) {
+		t.Error("cropped pixels differ from the content")
+	}
+
+	if _, _, _, _, err := findCropRect(solidImage(width, height, [4]uint8{9, 9, 9, 255}), width, height, 0); err == nil {
+		t.Error("a uniform image did not return an error")
+	}
+}
+
+func TestMakeComparison(t *testing.T) {
+	red, blue := [4]uint8{255, 0, 0, 255}, [4]uint8{0, 0, 255, 255}
+	background := [4]uint8{10, 20, 30, 255}
+	got, width, height := makeComparison(solidImage(5, 3, red), 5, 3, solidImage(4, 6, blue), 4, 6, 2, background)
+	if width != 5+2+4 || height != 6 {
+		t.Fatalf("got %dx%d, want 11x6", width, height)
+	}
+
+	at := func(x, y int) [4]uint8 {
+		var p [4]uint8
+		copy(p[:], got[(y*width+x)*4:])
+		return p
+	}
+	for _, tc := range []struct {
+		x, y int
+		want [4]uint8
+	}{
+		{0, 0, red}, {4, 2, red},
+		{5, 0, background}, {6, 5, background}, // The gap
+		{0, 3, background}, {4, 5, background}, // Below the shorter image
+		{7, 0, blue}, {10, 5, blue},
+	} {
+		if p := at(tc.x, tc.y); p != tc.want {
+			t.Errorf("(%d, %d) is %v, want %v", tc.x, tc.y, p, tc.want)
+		}
+	}
+}
+
+func TestRotateArbitraryCropMatchesQuarterTurns(t *testing.T) {
+	width, height := 21, 15
+	src := randomImage(width, height, 6)
+	got, w, h := rotateArbitrary(src, width, height, 180, false, [4]uint8{})
+	if w != width || h != height {
+		t.Fatalf("got %dx%d, want the original %dx%d", w, h, width, height)
+	}
+	want, _, _ := rotateImage(src, width, height, 2)
+	if diff := maxAbsDiff(got, want); diff > 1 {
+		t.Errorf("cropped 180° rotation differs from two quarter turns by %d", diff)
+	}
+}
+
+func TestPatchOrigins(t *testing.T) {
+	for _, tc := range []struct {
+		width, height, size, stride int
+		clampLast                   bool
+		want                        [][2]int
+	}{
+		{8, 8, 8, 8, true, [][2]int{{0, 0}}}, // One patch covering the whole image
+		{10, 4, 4, 4, true, [][2]int{{0, 0}, {4, 0}, {6, 0}}},
+		{10, 4, 4, 4, false, [][2]int{{0, 0}, {4, 0}}},
+		{6, 6, 4, 2, true, [][2]int{{0, 0}, {2, 0}, {0, 2}, {2, 2}}},
+	} {
+		got := patchOrigins(tc.width, tc.height, tc.size, tc.stride, tc.clampLast)
+		if fmt.Sprint(got) != fmt.Sprint(tc.want) {
+			t.Errorf("%dx%d, size %d, stride %d, clamp %v: got %v, want %v",
+				tc.width, tc.height, tc.size, tc.stride, tc.clampLast, got, tc.want)
+		}
+	}
+}
+
+func TestExtractPatchesWrapper(t *testing.T) {
+	width, height := 6, 6
+	src := randomImage(width, height, 7)
+	result := extractPatchesWrapper(js.Undefined(), []js.Value{imageDataToJS(src, width, height), js.ValueOf(6), js.ValueOf(6)}).(js.Value)
+	if result.Length() != 1 {
+		t.Fatalf("got %d patches, want 1", result.Length())
+	}
+	data, w, h, err := readImageData(result.Index(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w != width || h != height || !bytes.Equal(data, src) {
+		t.Error("the single patch is not the whole image")
+	}
+
+	result = extractPatchesWrapper(js.Undefined(), []js.Value{imageDataToJS(src, width, height), js.ValueOf(7), js.ValueOf(1)}).(js.Value)
+	if result.Get("error").Type() != js.TypeString {
+		t.Error("a patch larger than the image was accepted")
+	}
+}
+
+func TestApplyWarpPoints(t *testing.T) {
+	width, height := 24, 20
+	src := randomImage(width, height, 9)
+	if got := applyWarpPoints(src, width, height, nil); !bytes.Equal(got, src) {
+		t.Error("no points changed the image")
+	}
+
+	point := warpPoint{fromX: 10, fromY: 10, toX: 12, toY: 10, radius: 6}
+	got := applyWarpPoints(src, width, height, []warpPoint{point})
+	dst, from := (10*width+12)*4, (10*width+10)*4
+	if !bytes.Equal(got[dst:dst+4], src[from:from+4]) {
+		t.Errorf("destination is %v, want the source pixel %v", got[dst:dst+4], src[from:from+4])
+	}
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			dx, dy := float64(x)-point.toX, float64(y)-point.toY
+			idx := (y*width + x) * 4
+			if dx*dx+dy*dy >= point.radius*point.radius && !bytes.Equal(got[idx:idx+4], src[idx:idx+4]) {
+				t.Fatalf("(%d, %d) outside the radius changed", x, y)
+			}
+		}
+	}
+}
+
+func TestResizeBilinearRoundTrip(t *testing.T) {
+	width, height := 24, 16
+	src := smoothImage(width, height)
+	up := resizeBilinear(src, width, height, width*4, height*4)
+	back := resizeBilinear(up, width*4, height*4, width, height)
+	if diff := maxAbsDiff(back, src); diff > 2 {
+		t.Errorf("4x up and back down differs from the original by %d", diff)
+	}
+
+	img := imageDataToJS(src, width, height)
+	for _, dims := range [][2]interface{}{{0, 10}, {10, -3}, {10.5, 10}, {"10", 10}} {
+		result := resizeImageWrapper(js.Undefined(), []js.Value{img, js.ValueOf(dims[0]), js.ValueOf(dims[1])}).(js.Value)
+		if result.Get("error").Type() != js.TypeString {
+			t.Errorf("target %v x %v was accepted", dims[0], dims[1])
+		}
+	}
+}
+
+func TestResizeModes(t *testing.T) {
+	width, height, factor := 5, 4, 3
+	src := randomImage(width, height, 13)
+	got, err := resizeImage(src, width, height, width*factor, height*factor, "nearest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	outWidth := width * factor
+	for y := 0; y < height*factor; y++ {
+		for x := 0; x < outWidth; x++ {
+			idx, from := (y*outWidth+x)*4, ((y/factor)*width+x/factor)*4
+			if !bytes.Equal(got[idx:idx+4], src[from:from+4]) {
+				t.Fatalf("(%d, %d) is %v, want source pixel %v", x, y, got[idx:idx+4], src[from:from+4])
+			}
+		}
+	}
+
+	// Bicubic overshoot at a hard edge is clamped, and flat areas stay flat
+	edge := splitImage(4, 4, 2, [4]uint8{0, 0, 0, 255}, [4]uint8{255, 255, 255, 255})
+	if got, err = resizeImage(edge, 4, 4, 16, 16, "bicubic"); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got[:4], []uint8{0, 0, 0, 255}) || !bytes.Equal(got[len(got)-4:], []uint8{255, 255, 255, 255}) {
+		t.Errorf("bicubic corners are %v and %v", got[:4], got[len(got)-4:])
+	}
+
+	if _, err := resizeImage(src, width, height, 8, 8, "lanczos"); err == nil {
+		t.Error("an unknown mode was accepted")
+	}
+}
+
+func TestRotateImageQuarterTurns(t *testing.T) {
+	width, height := 7, 4
+	src := randomImage(width, height, 14)
+
+	data, w, h := src, width, height
+	for turn := 1; turn <= 4; turn++ {
+		data, w, h = rotateImage(data, w, h, 1)
+		if turn%2 == 1 && (w != height || h != width) {
+			t.Fatalf("after %d turns: %dx%d, want %dx%d", turn, w, h, height, width)
+		}
+	}
+	if w != width || h != height || !bytes.Equal(data, src) {
+		t.Error("four quarter turns did not reproduce the input")
+	}
+
+	// Clockwise: the top-left pixel ends up top-right
+	data, w, _ = rotateImage(src, width, height, 1)
+	if corner := data[(w-1)*4 : w*4]; !bytes.Equal(corner, src[:4]) {
+		t.Errorf("top-right after a quarter turn is %v, want the original top-left %v", corner, src[:4])
+	}
+
+	result := rotateImageWrapper(js.Undefined(), []js.Value{imageDataToJS(src, width, height), js.ValueOf(45)}).(js.Value)
+	if result.Get("error").Type() != js.TypeString {
+		t.Error("45 degrees was accepted")
+	}
+}
+
+func TestFlipImage(t *testing.T) {
+	// A 3x2 pattern with every pixel distinct
+	width, height := 3, 2
+	src := make([]uint8, width*height*4)
+	for i := 0; i < width*height; i++ {
+		copy(src[i*4:], []uint8{uint8(i * 10), uint8(i), 0, uint8(200 + i)})
+	}
+	pixel := func(data []uint8, x, y int) []uint8 { return data[(y*width+x)*4 : (y*width+x)*4+4] }
+
+	for _, horizontal := range []bool{true, false} {
+		got := flipImage(src, width, height, horizontal)
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				fx, fy := x, height-1-y
+				if horizontal {
+					fx, fy = width-1-x, y
+				}
+				if !bytes.Equal(pixel(got, x, y), pixel(src, fx, fy)) {
+					t.Fatalf("horizontal %v: (%d, %d) is %v, want %v", horizontal, x, y, pixel(got, x, y), pixel(src, fx, fy))
+				}
+			}
+		}
+		if !bytes.Equal(flipImage(got, width, height, horizontal), src) {
+			t.Errorf("horizontal %v: flipping twice did not restore the original", horizontal)
+		}
+	}
+}
+
+func TestMakeContactSheet(t *testing.T) {
+	colors := [][4]uint8{{255, 0, 0, 255}, {0, 255, 0, 255}, {0, 0, 255, 255}, {255, 255, 0, 255}, {0, 255, 255, 255}}
+	sizes := [][2]int{{20, 10}, {10, 10}, {30, 30}, {20, 40}, {10, 5}} // Thumbnails 5, 10, 10, 20, and 5 tall
+	images := make([]contactImage, len(colors))
+	for i, c := range colors {
+		images[i] = contactImage{solidImage(sizes[i][0], sizes[i][1], c), sizes[i][0], sizes[i][1]}
+	}
+	background := [4]uint8{9, 9, 9, 255}
+	got, width, height := makeContactSheet(images, 10, 3, 2, background)
+
+	// Three 10-pixel columns with two gaps; rows 10 and 20 tall with one gap
+	if width != 34 || height != 32 {
+		t.Fatalf("got %dx%d, want 34x32", width, height)
+	}
+	at := func(x, y int) [4]uint8 { return [4]uint8(got[(y*width+x)*4:]) }
+	for _, tc := range []struct {
+		x, y int
+		want [4]uint8
+	}{
+		{5, 2, colors[0]}, {5, 7, background}, // Below the short first thumbnail
+		{10, 5, background}, {11, 20, background}, // The column gap
+		{27, 9, colors[2]},
+		{5, 10, background}, {20, 11, background}, // The row gap
+		{5, 31, colors[3]}, {15, 16, colors[4]}, {15, 17, background},
+	} {
+		if p := at(tc.x, tc.y); p != tc.want {
+			t.Errorf("(%d, %d) is %v, want %v", tc.x, tc.y, p, tc.want)
+		}
+	}
+	// The empty cell after the last image
+	for y := 12; y < height; y++ {
+		for x := 24; x < width; x++ {
+			if p := at(x, y); p != background {
+				t.Fatalf("empty cell pixel (%d, %d) is %v, want the background", x, y, p)
+			}
+		}
+	}
+}
@@ -0,0 +1,91 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"syscall/js"
+)
+
+// logLevel orders the verbosity levels accepted by setLogLevel.
+type logLevel int
+
+const (
+	logLevelSilent logLevel = iota
+	logLevelError
+	logLevelInfo
+	logLevelDebug
+)
+
+var logLevelNames = map[string]logLevel{
+	"silent": logLevelSilent,
+	"error":  logLevelError,
+	"info":   logLevelInfo,
+	"debug":  logLevelDebug,
+}
+
+// currentLogLevel gates every console message the module prints. It defaults to "error" so
+// that production pages only see failures; "info" adds timings and configuration changes,
+// and "debug" adds the per-call tracing. currentLogLevelMu guards it.
+var (
+	currentLogLevelMu sync.RWMutex
+	currentLogLevel   = logLevelError
+)
+
+// logOutput receives every printed line; the console in the browser.
+var logOutput io.Writer = os.Stdout
+
+// setLogLevelWrapper exposes setLogLevel to JavaScript.
+// It expects a level string ("silent", "error", "info", or "debug") and returns null or an
+// error object.
+func setLogLevelWrapper(this js.Value, args []js.Value) interface{} {
+	if len(args) < 1 || args[0].Type() != js.TypeString {
+		return createError("Invalid arguments for setLogLevel: expected 1 (level string)")
+	}
+	if err := setLogLevel(args[0].String()); err != nil {
+		return createError(err.Error())
+	}
+	return nil
+}
+
+// setLogLevel selects which messages are printed.
+func setLogLevel(name string) error {
+	level, ok := logLevelNames[name]
+	if !ok {
+		return fmt.Errorf("Invalid log level '%s': expected \"silent\", \"error\", \"info\", or \"debug\"", name)
+	}
+	currentLogLevelMu.Lock()
+	currentLogLevel = level
+	currentLogLevelMu.Unlock()
+	logInfo("Log level set to '%s'", name)
+	return nil
+}
+
+// logf prints a formatted line if the current level includes `level`.
+func logf(level logLevel, format string, args ...interface{}) {
+	currentLogLevelMu.RLock()
+	enabled := level <= currentLogLevel
+	currentLogLevelMu.RUnlock()
+	if enabled {
+		fmt.Fprintf(logOutput, format+"\n", args...)
+	}
+}
+
+// logError logs failures and recovered panics.
+func logError(format string, args ...interface{}) {
+	logf(logLevelError, format, args...)
+}
+
+// logInfo logs timings and configuration changes.
+func logInfo(format string, args ...interface{}) {
+	logf(logLevelInfo, format, args...)
+}
+
+// logDebug logs per-call tracing.
+func logDebug(format string, args ...interface{}) {
+	logf(logLevelDebug, format, args...)
+}
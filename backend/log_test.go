@@ -0,0 +1,61 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestLogLevels(t *testing.T) {
+	var buf bytes.Buffer
+	logOutput = &buf
+	defer func() {
+		logOutput = os.Stdout
+		setLogLevel("error")
+	}()
+
+	if err := setLogLevel("debug"); err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	logDebug("trace %d", 1)
+	logError("failure %d", 2)
+	if got := buf.String(); got != "trace 1\nfailure 2\n" {
+		t.Errorf("debug level printed %q", got)
+	}
+
+	if err := setLogLevel("silent"); err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	logError("failure")
+	logInfo("timing")
+	logDebug("trace")
+	if buf.Len() != 0 {
+		t.Errorf("silent level printed %q", buf.String())
+	}
+
+	if err := setLogLevel("error"); err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	logInfo("timing")
+	logError("failure")
+	if got := buf.String(); got != "failure\n" {
+		t.Errorf("error level printed %q", got)
+	}
+
+	// An unknown name is rejected and leaves the level alone
+	if err := setLogLevel("verbose"); err == nil || !strings.Contains(err.Error(), "verbose") {
+		t.Errorf("unknown level accepted: %v", err)
+	}
+	buf.Reset()
+	logInfo("timing")
+	if buf.Len() != 0 {
+		t.Errorf("rejected level changed output: %q", buf.String())
+	}
+}
@@ -0,0 +1,201 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"syscall/js"
+	"time"
+)
+
+// lutCache holds the tables built by buildLUT, keyed by handle. lutCacheMu guards the map
+// and nextLUTHandle; the tables themselves are never modified once cached.
+var (
+	lutCacheMu    sync.Mutex
+	lutCache      = map[int]*[256]uint8{}
+	nextLUTHandle = 1
+)
+
+// toneLUT builds the lookup table for the "tone" filter from resolved params. Contrast
+// scales around 128 and brightness is added first; gamma is then applied to the clamped
+// result as 255 * (v/255)^(1/gamma).
+func toneLUT(params filterParams) [256]uint8 {
+	brightness := params.num("brightness")
+	contrast := 1 + params.num("contrast")/100
+	gamma := params.num("gamma")
+
+	var lut [256]uint8
+	for i := range lut {
+		v := clampFloat64((float64(i)-128)*contrast+128+brightness, 0, 255)
+		v = 255 * math.Pow(v/255, 1/gamma)
+		lut[i] = uint8(clampFloat64(v+0.5, 0, 255))
+	}
+	return lut
+}
+
+// buildLUTWrapper builds a "tone" lookup table once for reuse across many images.
+// It expects an optional params object { brightness, contrast, gamma } (see the "tone"
+// filter in listFilters).
+// It returns a numeric handle for use with applyLUT and freeLUT, or an error object.
+func buildLUTWrapper(this js.Value, args []js.Value) interface{} {
+	var paramsJS js.Value
+	if len(args) > 0 {
+		paramsJS = args[0]
+	}
+	params, err := readFilterParams(paramsJS)
+	if err != nil {
+		return createError(err.Error())
+	}
+	spec, _ := findFilterSpec("tone")
+	params, err = spec.resolveParams(params)
+	if err != nil {
+		return createError(err.Error())
+	}
+
+	lut := toneLUT(params)
+	lutCacheMu.Lock()
+	handle := nextLUTHandle
+	nextLUTHandle++
+	lutCache[handle] = &lut
+	lutCacheMu.Unlock()
+
+	logDebug("buildLUTWrapper: built lookup table (handle %d)", handle)
+	return handle
+}
+
+// applyLUTWrapper applies a cached lookup table to an image.
+// It expects a handle from buildLUT and imageData { width, height, data: Uint8ClampedArray }.
+// It returns the processed Uint8ClampedArray or an error object.
+func applyLUTWrapper(this js.Value, args []js.Value) interface{} {
+	startTime := time.Now()
+	logDebug("applyLUTWrapper called")
+
+	if len(args) < 2 {
+		return createError("Invalid number of arguments for applyLUT: expected 2 (handle, imageData)")
+	}
+	lut, err := lookupLUT(args[0])
+	if err != nil {
+		return createError(err.Error())
+	}
+	srcData, width, height, err := readImageData(args[1])
+	if err != nil {
+		return createError(err.Error())
+	}
+
+	resultData := applyChannelLUT(srcData, width, height, lut)
+
+	logInfo("applyLUTWrapper completed in %v", time.Since(startTime))
+	return bytesToJS(resultData)
+}
+
+// freeLUTWrapper releases a cached lookup table. Freeing an unknown handle is a no-op.
+func freeLUTWrapper(this js.Value, args []js.Value) interface{} {
+	if len(args) < 1 || args[0].Type() != js.TypeNumber {
+		return createError("Invalid arguments for freeLUT: expected 1 (handle)")
+	}
+	lutCacheMu.Lock()
+	delete(lutCache, args[0].Int())
+	lutCacheMu.Unlock()
+	return nil
+}
+
+// lookupLUT resolves a JS handle to its cached lookup table.
+func lookupLUT(handleJS js.Value) (*[256]uint8, error) {
+	if handleJS.Type() != js.TypeNumber {
+		return nil, errors.New("Invalid handle: expected a number")
+	}
+	lutCacheMu.Lock()
+	lut, ok := lutCache[handleJS.Int()]
+	lutCacheMu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("Unknown LUT handle %d (already freed?)", handleJS.Int())
+	}
+	return lut, nil
+}
+
+// applyCubeLUTWrapper wraps the applyCubeLUT logic for syscall/js interaction.
+// It expects imageData { width, height, data: Uint8ClampedArray }, the LUT values as a
+// Float32Array of lutSize³ RGB triples in 0-1 (the data lines of a .cube file, red
+// varying fastest), and lutSize.
+// It returns the graded Uint8ClampedArray or an error object.
+func applyCubeLUTWrapper(this js.Value, args []js.Value) interface{} {
+	startTime := time.Now()
+	logDebug("applyCubeLUTWrapper called")
+
+	if len(args) < 3 {
+		return createError("Invalid number of arguments for applyCubeLUT: expected 3 (imageData, lutData, lutSize)")
+	}
+	srcData, width, height, err := readImageData(args[0])
+	if err != nil {
+		return createError(err.Error())
+	}
+	lut, err := readFloat32s(args[1])
+	if err != nil {
+		return createError("Invalid lutData argument: expected a Float32Array")
+	}
+	if args[2].Type() != js.TypeNumber || args[2].Int() < 2 || args[2].Int() > 256 {
+		return createError("Invalid lutSize argument: expected a number between 2 and 256")
+	}
+	size := args[2].Int()
+	if len(lut) != size*size*size*3 {
+		return createError(fmt.Sprintf("Invalid lutData length %d: expected lutSize³ * 3 = %d", len(lut), size*size*size*3))
+	}
+
+	resultData := applyCubeLUT(srcData, width, height, lut, size)
+
+	logInfo("applyCubeLUTWrapper completed in %v", time.Since(startTime))
+	return bytesToJS(resultData)
+}
+
+// applyCubeLUT maps every pixel through a 3D color lookup table (internal logic). The
+// table holds size³ RGB triples in 0-1 laid out as in a .cube file, entry
+// (r + g*size + b*size²) being the output for input color (r, g, b)/(size-1). Colors
+// between lattice points are interpolated trilinearly from the eight surrounding entries.
+// Alpha passes through.
+func applyCubeLUT(srcData []uint8, width, height int, lut []float32, size int) []uint8 {
+	scale := float64(size-1) / 255
+	resultData := make([]uint8, len(srcData))
+	parallelRows(height, func(startY, endY int) {
+		for i := startY * width * 4; i < endY*width*4; i += 4 {
+			var lo [3]int
+			var frac [3]float64
+			for c := 0; c < 3; c++ {
+				pos := float64(srcData[i+c]) * scale
+				lo[c] = min(int(pos), size-2)
+				frac[c] = pos - float64(lo[c])
+			}
+
+			var out [3]float64
+			for corner := 0; corner < 8; corner++ {
+				weight := 1.0
+				var idx [3]int
+				for c := 0; c < 3; c++ {
+					if corner&(1<<c) != 0 {
+						idx[c] = lo[c] + 1
+						weight *= frac[c]
+					} else {
+						idx[c] = lo[c]
+						weight *= 1 - frac[c]
+					}
+				}
+				if weight == 0 {
+					continue
+				}
+				entry := ((idx[2]*size+idx[1])*size + idx[0]) * 3
+				for c := 0; c < 3; c++ {
+					out[c] += weight * float64(lut[entry+c])
+				}
+			}
+
+			for c := 0; c < 3; c++ {
+				resultData[i+c] = uint8(clampFloat64(out[c]*255+0.5, 0, 255))
+			}
+			resultData[i+3] = srcData[i+3]
+		}
+	})
+	return resultData
+}
@@ -0,0 +1,81 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import (
+	"bytes"
+	"syscall/js"
+	"testing"
+)
+
+func TestApplyLUTMatchesTone(t *testing.T) {
+	width, height := 10, 6
+	src := randomImage(width, height, 1)
+	imgJS := imageDataToJS(src, width, height)
+
+	for _, params := range []map[string]interface{}{
+		{},
+		{"brightness": -30, "contrast": 50},
+		{"gamma": 2.2},
+		{"brightness": 15, "contrast": -40, "gamma": 0.6},
+	} {
+		resolved := filterParams{}
+		for k, v := range params {
+			resolved[k] = js.ValueOf(v).Float()
+		}
+		want, err := applyFilter(src, width, height, "tone", resolved)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(params) == 0 && !bytes.Equal(want, src) {
+			t.Error("tone with default params is not the identity")
+		}
+
+		handle := buildLUTWrapper(js.Undefined(), []js.Value{js.ValueOf(params)})
+		got := jsBytes(applyLUTWrapper(js.Undefined(), []js.Value{js.ValueOf(handle), imgJS}))
+		if !bytes.Equal(got, want) {
+			t.Errorf("params %v: applyLUT differs from the tone filter", params)
+		}
+		freeLUTWrapper(js.Undefined(), []js.Value{js.ValueOf(handle)})
+		if jsBytes(applyLUTWrapper(js.Undefined(), []js.Value{js.ValueOf(handle), imgJS})) != nil {
+			t.Errorf("handle %v still works after freeLUT", handle)
+		}
+	}
+}
+
+// cubeLUT fills a size³ .cube-layout table with f applied to each lattice color in 0-1.
+func cubeLUT(size int, f func(r, g, b float64) [3]float64) []float32 {
+	lut := make([]float32, 0, size*size*size*3)
+	for b := 0; b < size; b++ {
+		for g := 0; g < size; g++ {
+			for r := 0; r < size; r++ {
+				out := f(float64(r)/float64(size-1), float64(g)/float64(size-1), float64(b)/float64(size-1))
+				lut = append(lut, float32(out[0]), float32(out[1]), float32(out[2]))
+			}
+		}
+	}
+	return lut
+}
+
+func TestApplyCubeLUT(t *testing.T) {
+	width, height := 16, 16
+	src := randomImage(width, height, 2)
+	for i := 3; i < len(src); i += 8 {
+		src[i] = 77
+	}
+
+	identity := cubeLUT(17, func(r, g, b float64) [3]float64 { return [3]float64{r, g, b} })
+	if got := applyCubeLUT(src, width, height, identity, 17); !bytes.Equal(got, src) {
+		t.Errorf("identity LUT changed the image by up to %d", maxAbsDiff(got, src))
+	}
+
+	// A linear mapping is reproduced exactly even by the smallest lattice
+	swap := cubeLUT(2, func(r, g, b float64) [3]float64 { return [3]float64{b, g, r} })
+	got := applyCubeLUT(src, width, height, swap, 2)
+	for i := 0; i < len(src); i += 4 {
+		if want := []uint8{src[i+2], src[i+1], src[i], src[i+3]}; !bytes.Equal(got[i:i+4], want) {
+			t.Fatalf("pixel %d: got %v, want %v", i/4, got[i:i+4], want)
+		}
+	}
+}
@@ -0,0 +1,660 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import (
+	"math"
+	"math/rand"
+	"strings"
+	"sync"
+	"syscall/js"
+	"testing"
+	"time"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// randomImage returns width x height RGBA pixels of seeded noise with opaque alpha.
+func randomImage(width, height int, seed int64) []uint8 {
+	rng := rand.New(rand.NewSource(seed))
+	data := make([]uint8, width*height*4)
+	for i := 0; i < len(data); i += 4 {
+		data[i] = uint8(rng.Intn(256))
+		data[i+1] = uint8(rng.Intn(256))
+		data[i+2] = uint8(rng.Intn(256))
+		data[i+3] = 255
+	}
+	return data
+}
+
+// solidImage returns width x height RGBA pixels all set to c.
+func solidImage(width, height int, c [4]uint8) []uint8 {
+	data := make([]uint8, width*height*4)
+	for i := 0; i < len(data); i += 4 {
+		copy(data[i:i+4], c[:])
+	}
+	return data
+}
+
+// maxAbsDiff returns the largest per-byte difference between a and b.
+func maxAbsDiff(a, b []uint8) int {
+	worst := 0
+	for i := range a {
+		worst = max(worst, abs(int(a[i])-int(b[i])))
+	}
+	return worst
+}
+
+// channelMatrix returns a rows x cols matrix that looks like an image channel: smooth
+// gradients and waves in 0-255 plus seeded noise, so it has a few dominant singular values
+// and a noisy tail.
+func channelMatrix(rows, cols int, seed int64) *mat.Dense {
+	rng := rand.New(rand.NewSource(seed))
+	m := mat.NewDense(rows, cols, nil)
+	for y := 0; y < rows; y++ {
+		for x := 0; x < cols; x++ {
+			v := 100 + 60*float64(x)/float64(cols) + 40*math.Sin(float64(y)/9)*math.Cos(float64(x)/13)
+			m.Set(y, x, clampFloat64(v+rng.Float64()*30-15, 0, 255))
+		}
+	}
+	return m
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+func TestConvolveFloat32MatchesFloat64(t *testing.T) {
+	width, height := 67, 45
+	src := randomImage(width, height, 1)
+	kernels := map[string][]float64{
+		"blur":    convolutionKernels["blur"],
+		"sharpen": convolutionKernels["sharpen"],
+		"edge":    convolutionKernels["edge"],
+		"emboss":  convolutionKernels["emboss"],
+	}
+	random := make([]float64, 49)
+	rng := rand.New(rand.NewSource(2))
+	for i := range random {
+		random[i] = rng.Float64()*2 - 1
+	}
+	kernels["random7x7"] = random
+
+	for name, kernel := range kernels {
+		size := 3
+		if len(kernel) == 49 {
+			size = 7
+		}
+		fast := make([]uint8, len(src))
+		precise := make([]uint8, len(src))
+		convolve(src, width, height, kernel, size, convolutionBiases[name], clampEdges, false, fast, nil, nil)
+		convolve(src, width, height, kernel, size, convolutionBiases[name], clampEdges, true, precise, nil, nil)
+		if diff := maxAbsDiff(fast, precise); diff > 1 {
+			t.Errorf("%s: float32 and float64 outputs differ by %d, want at most 1", name, diff)
+		}
+	}
+}
+
+func TestCustomFilterPrecise(t *testing.T) {
+	width, height := 31, 17
+	src := randomImage(width, height, 3)
+	params := filterParams{"kernel": []float64{0, -1, 0, -1, 5, -1, 0, -1, 0}, "size": 3.0}
+	fast, err := applyFilter(src, width, height, "custom", params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	params["precise"] = true
+	precise, err := applyFilter(src, width, height, "custom", params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := make([]uint8, len(src))
+	convolve(src, width, height, convolutionKernels["sharpen"], 3, 0, clampEdges, true, want, nil, nil)
+	if diff := maxAbsDiff(precise, want); diff != 0 {
+		t.Errorf("precise custom kernel differs from the float64 convolution by %d", diff)
+	}
+	if diff := maxAbsDiff(fast, want); diff > 1 {
+		t.Errorf("float32 custom kernel differs from the float64 convolution by %d, want at most 1", diff)
+	}
+}
+
+// BenchmarkConvolveLargeBlur compares float32 and float64 accumulation for a 9x9 box blur
+// of a 1024x1024 image.
+func BenchmarkConvolveLargeBlur(b *testing.B) {
+	width, height, size := 1024, 1024, 9
+	src := randomImage(width, height, 1)
+	kernel := make([]float64, size*size)
+	for i := range kernel {
+		kernel[i] = 1 / float64(size*size)
+	}
+	dst := make([]uint8, len(src))
+	for _, bc := range []struct {
+		name    string
+		precise bool
+	}{{"float32", false}, {"float64", true}} {
+		b.Run(bc.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				convolve(src, width, height, kernel, size, 0, clampEdges, bc.precise, dst, nil, nil)
+			}
+		})
+	}
+}
+
+func TestSeparableProgressReachesTotal(t *testing.T) {
+	setConcurrency(2, 1)
+	defer setConcurrency(0, 0)
+	for _, size := range [][2]int{{7, 5}, {1, 9}, {13, 1}, {33, 17}} {
+		width, height := size[0], size[1]
+		reporter := newProgressReporter(height, func(float64) {})
+		applySeparable(randomImage(width, height, 4), width, height, gaussianKernel1D(1), clampEdges, reporter)
+		if reporter.done != int64(height) {
+			t.Errorf("%dx%d: credited %d rows, want %d", width, height, reporter.done, height)
+		}
+	}
+}
+
+func TestSeparableMatchesConvolve(t *testing.T) {
+	width, height := 41, 29
+	src := randomImage(width, height, 5)
+	kernel1D := gaussianKernel1D(1.5)
+	want := make([]uint8, len(src))
+	convolve(src, width, height, outerProduct(kernel1D), len(kernel1D), 0, clampEdges, true, want, nil, nil)
+	got := applySeparable(src, width, height, kernel1D, clampEdges, nil)
+	if diff := maxAbsDiff(got, want); diff > 1 {
+		t.Errorf("separable blur differs from the 2D convolution by %d, want at most 1", diff)
+	}
+}
+
+// outerProduct returns the row-major 2D kernel k kᵀ of a 1D kernel.
+func outerProduct(k []float64) []float64 {
+	kernel := make([]float64, 0, len(k)*len(k))
+	for _, wy := range k {
+		for _, wx := range k {
+			kernel = append(kernel, wy*wx)
+		}
+	}
+	return kernel
+}
+
+// BenchmarkGaussianBlur compares the separable Gaussian blur with the equivalent 2D
+// convolution at sigma 3 (a 19x19 kernel) on a 512x512 image.
+func BenchmarkGaussianBlur(b *testing.B) {
+	width, height := 512, 512
+	src := randomImage(width, height, 1)
+	kernel1D := gaussianKernel1D(3)
+	b.Run("separable", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			applySeparable(src, width, height, kernel1D, clampEdges, nil)
+		}
+	})
+	b.Run("naive", func(b *testing.B) {
+		kernel := outerProduct(kernel1D)
+		dst := make([]uint8, len(src))
+		for i := 0; i < b.N; i++ {
+			convolve(src, width, height, kernel, len(kernel1D), 0, clampEdges, false, dst, nil, nil)
+		}
+	})
+}
+
+func TestEmbossSolidColorIsMidGray(t *testing.T) {
+	width, height := 16, 12
+	for _, c := range [][4]uint8{{0, 0, 0, 255}, {200, 30, 90, 255}, {255, 255, 255, 255}} {
+		got, err := applyFilter(solidImage(width, height, c), width, height, "emboss", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < len(got); i += 4 {
+			for ch := 0; ch < 3; ch++ {
+				if abs(int(got[i+ch])-128) > 1 {
+					t.Fatalf("color %v: pixel %d channel %d is %d, want about 128", c, i/4, ch, got[i+ch])
+				}
+			}
+			if got[i+3] != c[3] {
+				t.Fatalf("color %v: alpha %d, want %d", c, got[i+3], c[3])
+			}
+		}
+	}
+}
+
+func TestRecoverToErrorReturnsErrorObject(t *testing.T) {
+	for name, fn := range map[string]func(js.Value, []js.Value) interface{}{
+		"panicInWrapper": func(this js.Value, args []js.Value) interface{} {
+			var data []uint8
+			return data[args[0].Int()] // Index out of range
+		},
+		"panicInWorker": func(this js.Value, args []js.Value) interface{} {
+			parallelRows(args[0].Int(), func(startY, endY int) {
+				if startY == 0 {
+					panic("worker failed")
+				}
+			})
+			return nil
+		},
+	} {
+		result, ok := recoverToError(name, fn)(js.Undefined(), []js.Value{js.ValueOf(200)}).(js.Value)
+		if !ok || result.Type() != js.TypeObject || result.Get("error").Type() != js.TypeString {
+			t.Errorf("%s returned %v, want an error object", name, result)
+			continue
+		}
+		if msg := result.Get("error").String(); !strings.HasPrefix(msg, "Internal error in "+name+": ") {
+			t.Errorf("%s: error %q does not name the function", name, msg)
+		}
+	}
+
+	// A wrapper that does not panic returns its own result
+	if got := recoverToError("ok", func(this js.Value, args []js.Value) interface{} { return 7 })(js.Undefined(), nil); got != 7 {
+		t.Errorf("got %v, want 7", got)
+	}
+}
+
+func TestRankOnePowerIterationMatchesSVD(t *testing.T) {
+	for _, dims := range [][2]int{{60, 80}, {80, 60}, {1, 30}} {
+		m := channelMatrix(dims[0], dims[1], 1)
+		got, sigma := rankOnePowerIteration(m)
+		want, kept := compressMatrixSVDDirect(m, 1, 0)
+		var diff mat.Dense
+		diff.Sub(got, want)
+		if norm := mat.Norm(&diff, 2); norm > 1e-6*kept[0] {
+			t.Errorf("%dx%d: rank-1 reconstructions differ by %g", dims[0], dims[1], norm)
+		}
+		if math.Abs(sigma[0]-kept[0]) > 1e-9*kept[0] {
+			t.Errorf("%dx%d: σ = %v, want %v", dims[0], dims[1], sigma[0], kept[0])
+		}
+	}
+}
+
+// BenchmarkRankOneSVD compares power iteration with a full factorization for the rank-1
+// approximation of a 300x400 channel.
+func BenchmarkRankOneSVD(b *testing.B) {
+	m := channelMatrix(300, 400, 1)
+	b.Run("power-iteration", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			rankOnePowerIteration(m)
+		}
+	})
+	b.Run("svd", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			compressMatrixSVDDirect(m, 1, 0)
+		}
+	})
+}
+
+// smoothImage returns width x height opaque RGBA pixels of gentle gradients and waves, the
+// kind of content low-rank SVD reconstructs well.
+func smoothImage(width, height int) []uint8 {
+	data := make([]uint8, width*height*4)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			idx := (y*width + x) * 4
+			data[idx] = uint8(255 * x / max(width-1, 1))
+			data[idx+1] = uint8(255 * y / max(height-1, 1))
+			data[idx+2] = uint8(128 + 100*math.Sin(float64(x+y)/10))
+			data[idx+3] = 255
+		}
+	}
+	return data
+}
+
+func TestCompressSVDPreview(t *testing.T) {
+	width, height := 160, 120
+	src := smoothImage(width, height)
+	ranks := [4]int32{10, 10, 10, 10}
+
+	start := time.Now()
+	full, _ := compressSVD(src, int32(width), int32(height), ranks, svdOptions{PreviewFactor: 1})
+	fullTime := time.Since(start)
+	start = time.Now()
+	preview, _ := compressSVD(src, int32(width), int32(height), ranks, svdOptions{PreviewFactor: 2})
+	previewTime := time.Since(start)
+
+	plain, _ := compressSVD(src, int32(width), int32(height), ranks, svdOptions{})
+	if diff := maxAbsDiff(full, plain); diff != 0 {
+		t.Errorf("factor 1 differs from the plain compression by %d", diff)
+	}
+	if len(preview) != len(src) {
+		t.Fatalf("preview has %d bytes, want the full-size %d", len(preview), len(src))
+	}
+	if diff := maxAbsDiff(preview, full); diff > 40 {
+		t.Errorf("preview differs from the full compression by %d", diff)
+	}
+	if previewTime >= fullTime {
+		t.Errorf("preview took %v, not faster than the full compression's %v", previewTime, fullTime)
+	}
+}
+
+func TestSetSVDKindEquivalent(t *testing.T) {
+	m := channelMatrix(40, 60, 2)
+	defer setSVDKind("thin")
+
+	var results []*mat.Dense
+	for _, kind := range []string{"full", "thin"} {
+		if err := setSVDKind(kind); err != nil {
+			t.Fatal(err)
+		}
+		for _, mm := range []*mat.Dense{m, mat.DenseCopyOf(m.T())} {
+			approx, _ := compressMatrixSVD(mm, 5, 0.5)
+			results = append(results, approx)
+		}
+	}
+	for i := 0; i < 2; i++ {
+		if !mat.EqualApprox(results[i], results[i+2], 1e-8) {
+			t.Errorf("full and thin SVD reconstructions differ (case %d)", i)
+		}
+	}
+	if err := setSVDKind("economy"); err == nil {
+		t.Error("unknown kind did not return an error")
+	}
+}
+
+func TestCompressMatrixSVDTransposed(t *testing.T) {
+	tall := channelMatrix(90, 30, 3)
+	got, gotKept := compressMatrixSVD(tall, 6, 0)
+	want, wantKept := compressMatrixSVDDirect(tall, 6, 0)
+	if !mat.EqualApprox(got, want, 1e-8) {
+		t.Error("transposed factorization of a tall matrix differs from the direct one")
+	}
+	if r, c := got.Dims(); r != 90 || c != 30 {
+		t.Errorf("result is %dx%d, want 90x30", r, c)
+	}
+	for i := range wantKept {
+		if math.Abs(gotKept[i]-wantKept[i]) > 1e-9*wantKept[0] {
+			t.Errorf("σ%d = %v, want %v", i, gotKept[i], wantKept[i])
+		}
+	}
+}
+
+func TestCompressSVDClampsAbsurdRank(t *testing.T) {
+	width, height := 20, 12
+	src := randomImage(width, height, 4)
+	absurd := int32(1e9)
+	got, stats := compressSVD(src, int32(width), int32(height), [4]int32{absurd, absurd, absurd, absurd}, svdOptions{})
+	for c := 0; c < 3; c++ {
+		if stats.Ranks[c] != height-1 {
+			t.Errorf("channel %d kept rank %d, want %d", c, stats.Ranks[c], height-1)
+		}
+	}
+	if maxAbsDiff(got, src) == 0 {
+		t.Error("the image was returned unchanged instead of compressed at the largest useful rank")
+	}
+	want, _ := compressSVD(src, int32(width), int32(height), [4]int32{11, 11, 11, 11}, svdOptions{})
+	if maxAbsDiff(got, want) != 0 {
+		t.Error("clamped result differs from compressing at rank 11")
+	}
+
+	// The wrapper reports the clamp on the result object, never on the data array, which
+	// for inPlace is the caller's own buffer
+	for _, inPlace := range []bool{false, true} {
+		img := imageDataToJS(src, width, height)
+		options := js.Global().Get("Object").New()
+		options.Set("inPlace", inPlace)
+		result := compressSVDWrapper(js.Undefined(), []js.Value{img, js.ValueOf(1e9), options}).(js.Value)
+		if note := result.Get("note"); note.Type() != js.TypeString || !strings.Contains(note.String(), "clamped to 11") {
+			t.Errorf("inPlace=%v: note is %v", inPlace, note)
+		}
+		if !result.Get("data").Get("note").IsUndefined() {
+			t.Errorf("inPlace=%v: note was set on the data array", inPlace)
+		}
+		if inPlace && !result.Get("data").Equal(img.Get("data")) {
+			t.Error("inPlace result is not the caller's array")
+		}
+	}
+	// An in-range rank still returns the bare array
+	result := compressSVDWrapper(js.Undefined(), []js.Value{imageDataToJS(src, width, height), js.ValueOf(5)}).(js.Value)
+	if !result.InstanceOf(js.Global().Get("Uint8ClampedArray")) {
+		t.Error("unclamped call did not return the data array")
+	}
+}
+
+func TestApplyFilterProgressFinishesOnce(t *testing.T) {
+	width, height := 40, 300
+	src := randomImage(width, height, 5)
+	for _, filterType := range []string{"blur", "gaussian", "sharpen", "median", "grayscale", "nlmeans"} {
+		var mu sync.Mutex
+		var reports []float64
+		record := func(f float64) {
+			mu.Lock()
+			reports = append(reports, f)
+			mu.Unlock()
+		}
+		if _, err := applyFilterWithProgress(src, width, height, filterType, nil, record); err != nil {
+			t.Fatal(err)
+		}
+
+		ones := 0
+		for i, f := range reports {
+			if f <= 0 || f > 1 || (i > 0 && f <= reports[i-1]) {
+				t.Errorf("%s: progress went %v", filterType, reports)
+				break
+			}
+			if f == 1 {
+				ones++
+			}
+		}
+		if ones != 1 || reports[len(reports)-1] != 1 {
+			t.Errorf("%s: reported 1.0 %d times, ending at %v; want once, at the end", filterType, ones, reports[len(reports)-1])
+		}
+	}
+
+	var reports []float64
+	if _, err := applyFilterWithProgress(src, width, height, "median", filterParams{"size": 4.0}, func(f float64) { reports = append(reports, f) }); err == nil {
+		t.Fatal("an even median size did not return an error")
+	}
+	if len(reports) != 0 {
+		t.Errorf("a failed run reported progress %v", reports)
+	}
+}
+
+func TestSVDErrorMap(t *testing.T) {
+	width, height := 40, 30
+	src := smoothImage(width, height)
+	noise := randomImage(10, 10, 7)
+	for y := 0; y < 10; y++ {
+		copy(src[((y+5)*width+25)*4:], noise[y*10*4:(y+1)*10*4])
+	}
+	compressed, _ := compressSVD(src, int32(width), int32(height), [4]int32{3, 3, 3, 3}, svdOptions{})
+	errorMap := svdErrorMap(src, compressed, width, height)
+
+	mean := func(x0, y0, x1, y1 int) float64 {
+		sum := 0
+		for y := y0; y < y1; y++ {
+			for x := x0; x < x1; x++ {
+				idx := (y*width + x) * 4
+				if errorMap[idx] != errorMap[idx+1] || errorMap[idx] != errorMap[idx+2] || errorMap[idx+3] != 255 {
+					t.Fatalf("(%d, %d) is %v, want opaque gray", x, y, errorMap[idx:idx+4])
+				}
+				sum += int(errorMap[idx])
+			}
+		}
+		return float64(sum) / float64((x1-x0)*(y1-y0))
+	}
+	detail, smooth := mean(25, 5, 35, 15), mean(0, 20, 20, 30)
+	if detail < 4*smooth || detail < 20 {
+		t.Errorf("mean error %.1f in the noisy patch, %.1f in the smooth area; want the patch to stand out", detail, smooth)
+	}
+
+	// Every channel off by 10 is an error of 10
+	if got := svdErrorMap(make([]uint8, 4), []uint8{10, 10, 10, 10}, 1, 1); got[0] != 10 {
+		t.Errorf("uniform error of 10 mapped to %d", got[0])
+	}
+}
+
+func TestCompressSVDIntoInPlace(t *testing.T) {
+	width, height := 30, 22
+	src := randomImage(width, height, 6)
+	for i := 3; i < len(src); i += 12 {
+		src[i] = 90 // Translucent pixels, so alpha is compressed too
+	}
+	ranks := [4]int32{4, 5, 6, 3}
+	want, wantStats := compressSVD(src, int32(width), int32(height), ranks, svdOptions{})
+
+	buf := append([]uint8(nil), src...)
+	got, stats := compressSVDInto(buf, buf, int32(width), int32(height), ranks, svdOptions{})
+	if &got[0] != &buf[0] {
+		t.Error("compressSVDInto did not return dst")
+	}
+	if diff := maxAbsDiff(got, want); diff != 0 {
+		t.Errorf("in-place result differs from out-of-place by %d", diff)
+	}
+	if stats != wantStats {
+		t.Errorf("in-place stats %+v, want %+v", stats, wantStats)
+	}
+}
+
+func TestApplyFilterRawKeepsNegativeResponses(t *testing.T) {
+	width, height := 10, 6
+	src := splitImage(width, height, 5, [4]uint8{0, 0, 0, 255}, [4]uint8{255, 255, 255, 200})
+	raw, err := applyFilterRaw(src, width, height, "edge", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clamped, err := applyFilter(src, width, height, "edge", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lo, hi := float32(0), float32(0)
+	for i, v := range raw {
+		if i%4 == 3 {
+			if v != float32(src[i]) {
+				t.Fatalf("alpha %d: got %v, want %d", i/4, v, src[i])
+			}
+			continue
+		}
+		if v < lo {
+			lo = v
+		}
+		hi = max(hi, v)
+		if want := uint8(clampFloat64(float64(v)+0.5, 0, 255)); clamped[i] != want {
+			t.Fatalf("value %d: applyFilter gave %d, the clamped raw response is %d", i, clamped[i], want)
+		}
+	}
+	if lo >= 0 || hi <= 255 {
+		t.Errorf("responses span [%v, %v], want both sides of the edge outside 0-255", lo, hi)
+	}
+
+	if _, err := applyFilterRaw(src, width, height, "median", nil); err == nil {
+		t.Error("a non-convolution filter was accepted")
+	}
+}
+
+func TestFractionalRankBlends(t *testing.T) {
+	m := channelMatrix(30, 40, 8)
+	lower, _ := compressMatrixSVD(m, 5, 0)
+	upper, _ := compressMatrixSVD(m, 6, 0)
+	var want mat.Dense
+	want.Add(lower, upper)
+	want.Scale(0.5, &want)
+
+	got, kept := compressMatrixSVD(m, 5, 0.5)
+	if !mat.EqualApprox(got, &want, 1e-8) {
+		t.Error("rank 5.5 is not the midpoint of the rank 5 and rank 6 reconstructions")
+	}
+	_, full := compressMatrixSVD(m, 6, 0)
+	if len(kept) != 6 || math.Abs(kept[5]-full[5]/2) > 1e-9*full[0] {
+		t.Errorf("kept %v, want the sixth singular value halved", kept)
+	}
+
+	// Through compressSVD, each value lands halfway between the two integer ranks, except
+	// where clamping to 0-255 hides the unclamped values
+	width, height := 24, 18
+	src := randomImage(width, height, 8)
+	at := func(rank int32, fraction float64) []uint8 {
+		got, _ := compressSVD(src, int32(width), int32(height), [4]int32{rank, rank, rank, rank}, svdOptions{RankFraction: fraction})
+		return got
+	}
+	half, four, five := at(4, 0.5), at(4, 0), at(5, 0)
+	for i := range half {
+		if min(int(four[i]), int(five[i])) == 0 || max(four[i], five[i]) == 255 {
+			continue
+		}
+		if mid := (int(four[i]) + int(five[i])) / 2; abs(int(half[i])-mid) > 1 {
+			t.Fatalf("byte %d: rank 4.5 gave %d, between %d and %d", i, half[i], four[i], five[i])
+		}
+	}
+}
+
+func TestCompressSVDSkipAlphaIsBitExact(t *testing.T) {
+	width, height := 26, 20
+	src := randomImage(width, height, 11)
+	rng := rand.New(rand.NewSource(11))
+	for i := 3; i < len(src); i += 4 {
+		src[i] = uint8(rng.Intn(256))
+	}
+	ranks := [4]int32{3, 3, 3, 3}
+	skip, compress := true, false
+
+	got, stats := compressSVD(src, int32(width), int32(height), ranks, svdOptions{SkipAlpha: &skip})
+	for i := 3; i < len(src); i += 4 {
+		if got[i] != src[i] {
+			t.Fatalf("pixel %d: skipped alpha changed from %d to %d", i/4, src[i], got[i])
+		}
+	}
+	if stats.Ranks[3] != 0 {
+		t.Errorf("skipped alpha reported rank %d, want 0", stats.Ranks[3])
+	}
+
+	got, _ = compressSVD(src, int32(width), int32(height), ranks, svdOptions{SkipAlpha: &compress})
+	alphaChanged := false
+	for i := 3; i < len(src); i += 4 {
+		alphaChanged = alphaChanged || got[i] != src[i]
+	}
+	if !alphaChanged {
+		t.Error("compressing noisy alpha at rank 3 left it unchanged")
+	}
+
+	// Opaque images skip alpha by default
+	opaque := randomImage(width, height, 12)
+	if _, stats = compressSVD(opaque, int32(width), int32(height), ranks, svdOptions{}); stats.Ranks[3] != 0 {
+		t.Errorf("opaque image compressed alpha at rank %d", stats.Ranks[3])
+	}
+}
+
+func TestBlurEdgeModes(t *testing.T) {
+	// Columns run 10, 30, ..., 150 and every row is the same
+	width, height := 8, 5
+	src := make([]uint8, width*height*4)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			v := uint8(10 + 20*x)
+			copy(src[(y*width+x)*4:], []uint8{v, v, v, 255})
+		}
+	}
+	plain, err := applyFilter(src, width, height, "blur", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// First and last column of the middle row: the 3x3 mean of each column with its
+	// neighbors, where the missing neighbor depends on the mode
+	for _, tc := range []struct {
+		mode        string
+		first, last uint8
+	}{
+		{"clamp", 17, 143},    // (10+10+30)/3 and (130+150+150)/3
+		{"reflect", 17, 143},  // A one-pixel mirror repeats the border pixel, like clamp
+		{"wrap", 63, 97},      // (150+10+30)/3 and (130+150+10)/3
+		{"constant", 80, 160}, // (200+10+30)/3 and (130+150+200)/3
+	} {
+		got, err := applyFilter(src, width, height, "blur", filterParams{"edgeMode": tc.mode, "fillColor": []float64{200, 200, 200}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		row := got[2*width*4 : 3*width*4]
+		if row[0] != tc.first || row[(width-1)*4] != tc.last {
+			t.Errorf("%s: edge columns %d and %d, want %d and %d", tc.mode, row[0], row[(width-1)*4], tc.first, tc.last)
+		}
+		// Interior columns see no border and keep the linear ramp
+		for x := 1; x < width-1; x++ {
+			if row[x*4] != uint8(10+20*x) {
+				t.Errorf("%s: interior column %d is %d, want %d", tc.mode, x, row[x*4], 10+20*x)
+			}
+		}
+		if tc.mode == "clamp" && maxAbsDiff(got, plain) != 0 {
+			t.Error("explicit clamp differs from the default edge handling")
+		}
+	}
+}
@@ -0,0 +1,37 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import "testing"
+
+func TestGenerateRadialMask(t *testing.T) {
+	size := 41
+	mask := generateRadialMask(size, size, 20.5, 20.5, 20, 20, 0.5)
+	at := func(x, y int) int { return int(mask[y*size+x]) }
+
+	if at(20, 20) != 255 {
+		t.Errorf("center is %d, want 255", at(20, 20))
+	}
+	for _, corner := range [][2]int{{0, 0}, {40, 0}, {0, 40}, {40, 40}} {
+		if v := at(corner[0], corner[1]); v != 0 {
+			t.Errorf("corner %v is %d, want 0", corner, v)
+		}
+	}
+	// Outward from the center the mask never rises, and the 10-pixel feather has no jumps
+	for x := 21; x < size; x++ {
+		step := at(x-1, 20) - at(x, 20)
+		if step < 0 || step > 40 {
+			t.Errorf("mask steps by %d between x=%d and x=%d", step, x-1, x)
+		}
+		if at(x, 20) != at(40-x, 20) || at(x, 20) != at(20, x) {
+			t.Errorf("mask is not symmetric at distance %d", x-20)
+		}
+	}
+
+	for i, v := range generateRadialMask(size, size, 20.5, 20.5, 15, 8, 0) {
+		if v != 0 && v != 255 {
+			t.Fatalf("hard-edged mask has value %d at %d", v, i)
+		}
+	}
+}
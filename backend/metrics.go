@@ -0,0 +1,182 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"syscall/js"
+	"time"
+)
+
+// readImagePair reads the two imageData arguments of a comparison and checks that they
+// have the same dimensions and data length.
+func readImagePair(a, b js.Value) ([]uint8, []uint8, int, int, error) {
+	dataA, width, height, err := readImageData(a)
+	if err != nil {
+		return nil, nil, 0, 0, err
+	}
+	dataB, widthB, heightB, err := readImageData(b)
+	if err != nil {
+		return nil, nil, 0, 0, err
+	}
+	if widthB != width || heightB != height {
+		return nil, nil, 0, 0, fmt.Errorf("Image dimensions do not match: %dx%d and %dx%d", width, height, widthB, heightB)
+	}
+	if len(dataA) != len(dataB) {
+		return nil, nil, 0, 0, fmt.Errorf("Image data lengths do not match: %d and %d", len(dataA), len(dataB))
+	}
+	return dataA, dataB, width, height, nil
+}
+
+// computePSNRWrapper wraps the computePSNR logic for syscall/js interaction.
+// It expects two imageData objects { width, height, data: Uint8ClampedArray } of the same
+// dimensions.
+// It returns the PSNR in dB (Infinity for identical images) or an error object.
+func computePSNRWrapper(this js.Value, args []js.Value) interface{} {
+	startTime := time.Now()
+	logDebug("computePSNRWrapper called")
+
+	if len(args) < 2 {
+		return createError("Invalid number of arguments for computePSNR: expected 2 (imageDataA, imageDataB)")
+	}
+	dataA, dataB, width, height, err := readImagePair(args[0], args[1])
+	if err != nil {
+		return createError(err.Error())
+	}
+
+	psnr := computePSNR(dataA, dataB, width, height)
+
+	logInfo("computePSNRWrapper completed in %v", time.Since(startTime))
+	return psnr
+}
+
+// computePSNR returns the peak signal-to-noise ratio 10 * log10(255² / MSE) of b against a
+// in dB (internal logic), with the mean squared error taken over the R, G, and B values of
+// every pixel; alpha is ignored. Identical images have no noise and give +Inf. Row chunks
+// sum their squared differences separately and the partial sums are added at the end.
+func computePSNR(a, b []uint8, width, height int) float64 {
+	chunkRows := currentChunkRows()
+	partials := make([]float64, rowChunkCount(height, chunkRows))
+	parallelRowsSized(height, chunkRows, func(startY, endY int) {
+		sum := 0.0
+		for i := startY * width * 4; i < endY*width*4; i += 4 {
+			for c := 0; c < 3; c++ {
+				d := float64(a[i+c]) - float64(b[i+c])
+				sum += d * d
+			}
+		}
+		partials[startY/chunkRows] = sum
+	})
+
+	sumSq := 0.0
+	for _, partial := range partials {
+		sumSq += partial
+	}
+	mse := sumSq / float64(width*height*3)
+	if mse == 0 {
+		return math.Inf(1)
+	}
+	return 10 * math.Log10(255*255/mse)
+}
+
+// ssimWindow is the side of the square windows computeSSIM compares.
+const ssimWindow = 8
+
+// computeSSIMWrapper wraps the computeSSIM logic for syscall/js interaction.
+// It expects two imageData objects { width, height, data: Uint8ClampedArray } of the same
+// dimensions.
+// It returns the mean SSIM in [-1, 1] (1 for identical images) or an error object.
+func computeSSIMWrapper(this js.Value, args []js.Value) interface{} {
+	startTime := time.Now()
+	logDebug("computeSSIMWrapper called")
+
+	if len(args) < 2 {
+		return createError("Invalid number of arguments for computeSSIM: expected 2 (imageDataA, imageDataB)")
+	}
+	dataA, dataB, width, height, err := readImagePair(args[0], args[1])
+	if err != nil {
+		return createError(err.Error())
+	}
+
+	ssim := computeSSIM(dataA, dataB, width, height)
+
+	logInfo("computeSSIMWrapper completed in %v", time.Since(startTime))
+	return ssim
+}
+
+// computeSSIM returns the structural similarity of b to a (internal logic): the mean, over
+// every position of an 8x8 window sliding one pixel at a time across the luminance planes,
+// of
+//
+//	SSIM = (2 μa μb + C1) (2 σab + C2) / ((μa² + μb² + C1) (σa² + σb² + C2))
+//
+// with C1 = (0.01 * 255)² and C2 = (0.03 * 255)² (Wang et al., 2004). Windows shrink to the
+// image size for images smaller than 8 pixels in either direction. Each window's sums of a,
+// b, a², b², and ab come from integral images (see computeIntegralImage), so the cost does
+// not depend on the window size.
+func computeSSIM(a, b []uint8, width, height int) float64 {
+	const (
+		c1 = (0.01 * 255) * (0.01 * 255)
+		c2 = (0.03 * 255) * (0.03 * 255)
+	)
+	lumaA := lumaPlane(a, width, height)
+	lumaB := lumaPlane(b, width, height)
+	aa := make([]float64, len(lumaA))
+	bb := make([]float64, len(lumaA))
+	ab := make([]float64, len(lumaA))
+	for i := range lumaA {
+		aa[i] = lumaA[i] * lumaA[i]
+		bb[i] = lumaB[i] * lumaB[i]
+		ab[i] = lumaA[i] * lumaB[i]
+	}
+	sumA := computeIntegralImage(lumaA, width, height)
+	sumB := computeIntegralImage(lumaB, width, height)
+	sumAA := computeIntegralImage(aa, width, height)
+	sumBB := computeIntegralImage(bb, width, height)
+	sumAB := computeIntegralImage(ab, width, height)
+
+	winW, winH := min(ssimWindow, width), min(ssimWindow, height)
+	n := float64(winW * winH)
+	// windowSum returns the sum of table over the window whose top-left corner is (x, y)
+	windowSum := func(table []float64, x, y int) float64 {
+		x1, y1 := x+winW-1, y+winH-1
+		sum := table[y1*width+x1]
+		if x > 0 {
+			sum -= table[y1*width+x-1]
+		}
+		if y > 0 {
+			sum -= table[(y-1)*width+x1]
+		}
+		if x > 0 && y > 0 {
+			sum += table[(y-1)*width+x-1]
+		}
+		return sum
+	}
+
+	positionsY := height - winH + 1
+	positionsX := width - winW + 1
+	chunkRows := currentChunkRows()
+	partials := make([]float64, rowChunkCount(positionsY, chunkRows))
+	parallelRowsSized(positionsY, chunkRows, func(startY, endY int) {
+		total := 0.0
+		for y := startY; y < endY; y++ {
+			for x := 0; x < positionsX; x++ {
+				muA := windowSum(sumA, x, y) / n
+				muB := windowSum(sumB, x, y) / n
+				varA := windowSum(sumAA, x, y)/n - muA*muA
+				varB := windowSum(sumBB, x, y)/n - muB*muB
+				covAB := windowSum(sumAB, x, y)/n - muA*muB
+				total += (2*muA*muB + c1) * (2*covAB + c2) / ((muA*muA + muB*muB + c1) * (varA + varB + c2))
+			}
+		}
+		partials[startY/chunkRows] = total
+	})
+
+	total := 0.0
+	for _, partial := range partials {
+		total += partial
+	}
+	return total / float64(positionsX*positionsY)
+}
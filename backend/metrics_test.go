@@ -0,0 +1,77 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import (
+	"math"
+	"strings"
+	"syscall/js"
+	"testing"
+)
+
+func TestComputePSNR(t *testing.T) {
+	width, height := 16, 12
+	src := randomImage(width, height, 1)
+	if got := computePSNR(src, src, width, height); !math.IsInf(got, 1) {
+		t.Errorf("identical images give %v dB, want +Inf", got)
+	}
+
+	// Every R, G, and B value off by 5 gives an MSE of 25; alpha is ignored
+	a := solidImage(width, height, [4]uint8{100, 120, 140, 255})
+	b := solidImage(width, height, [4]uint8{105, 115, 145, 10})
+	want := 10 * math.Log10(255*255/25.0)
+	if got := computePSNR(a, b, width, height); math.Abs(got-want) > 1e-9 {
+		t.Errorf("MSE 25 gives %v dB, want %v", got, want)
+	}
+
+	// Row chunks smaller than the image sum to the same result
+	setConcurrency(3, 5)
+	defer setConcurrency(0, 0)
+	if got := computePSNR(a, b, width, height); math.Abs(got-want) > 1e-9 {
+		t.Errorf("chunked MSE 25 gives %v dB, want %v", got, want)
+	}
+}
+
+func TestComputePSNRRejectsMismatchedSizes(t *testing.T) {
+	result := computePSNRWrapper(js.Undefined(), []js.Value{
+		imageDataToJS(randomImage(8, 8, 2), 8, 8),
+		imageDataToJS(randomImage(8, 9, 3), 8, 9),
+	}).(js.Value)
+	if msg := result.Get("error"); msg.Type() != js.TypeString || !strings.Contains(msg.String(), "do not match") {
+		t.Errorf("mismatched sizes returned %v", result)
+	}
+}
+
+func TestComputeSSIM(t *testing.T) {
+	width, height := 20, 14
+	src := randomImage(width, height, 4)
+	if got := computeSSIM(src, src, width, height); math.Abs(got-1) > 1e-9 {
+		t.Errorf("identical images give SSIM %v, want 1", got)
+	}
+	// Windows shrink for images smaller than 8 pixels
+	if got := computeSSIM(src[:5*3*4], src[:5*3*4], 5, 3); math.Abs(got-1) > 1e-9 {
+		t.Errorf("identical 5x3 images give SSIM %v, want 1", got)
+	}
+
+	noisy := make([]uint8, len(src))
+	copy(noisy, src)
+	for i := 0; i < len(noisy); i += 4 {
+		for c := 0; c < 3; c++ {
+			noisy[i+c] = uint8(clamp(int(noisy[i+c])+(i/4%7-3)*20, 0, 255))
+		}
+	}
+	if got := computeSSIM(src, noisy, width, height); got >= 0.99 || got < -1 {
+		t.Errorf("noisy copy gives SSIM %v, want below 0.99", got)
+	}
+}
+
+func TestComputeSSIMRejectsMismatchedSizes(t *testing.T) {
+	result := computeSSIMWrapper(js.Undefined(), []js.Value{
+		imageDataToJS(randomImage(10, 8, 5), 10, 8),
+		imageDataToJS(randomImage(8, 10, 6), 8, 10),
+	}).(js.Value)
+	if msg := result.Get("error"); msg.Type() != js.TypeString || !strings.Contains(msg.String(), "do not match") {
+		t.Errorf("mismatched sizes returned %v", result)
+	}
+}
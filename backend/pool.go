@@ -0,0 +1,178 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import (
+	"errors"
+	"math"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"syscall/js"
+)
+
+// workerPool is a fixed set of long-lived goroutines that run the chunk jobs submitted
+// by parallelRows, so that many small calls do not pay for spawning fresh goroutines.
+type workerPool struct {
+	jobs chan func()
+	quit chan struct{}
+	size int
+}
+
+// activeWorkerPool is the pool parallelRows submits to, or nil when work runs on
+// per-call goroutines. activeWorkerPoolMu guards it.
+var (
+	activeWorkerPoolMu sync.Mutex
+	activeWorkerPool   *workerPool
+)
+
+// Concurrency settings made by setConcurrency. Zero means unset: parallelRows then uses
+// CHUNK_SIZE rows per chunk and one goroutine per chunk, and compressSVD one goroutine per
+// CPU for filling and rebuilding.
+var (
+	maxGoroutinesSetting atomic.Int64
+	chunkRowsSetting     atomic.Int64
+)
+
+// setConcurrencyWrapper exposes setConcurrency to JavaScript.
+// It expects the maximum number of goroutines and the rows per chunk, both positive
+// integers, and returns null or an error object.
+func setConcurrencyWrapper(this js.Value, args []js.Value) interface{} {
+	if len(args) < 2 {
+		return createError("Invalid number of arguments for setConcurrency: expected 2 (maxGoroutines, chunkRows)")
+	}
+	for _, v := range args[:2] {
+		if v.Type() != js.TypeNumber || v.Float() < 1 || v.Float() != math.Trunc(v.Float()) {
+			return createError("Invalid concurrency settings: maxGoroutines and chunkRows must be positive integers")
+		}
+	}
+	setConcurrency(args[0].Int(), args[1].Int())
+	return nil
+}
+
+// setConcurrency limits how many goroutines parallelRows and compressSVD run at once and
+// sets the rows per parallelRows chunk, so deployments can tune for their environment
+// (e.g. a browser main thread, where goroutines only add scheduling overhead) without
+// rebuilding. A running worker pool keeps its own size (see initWorkerPool).
+func setConcurrency(maxGoroutines, chunkRows int) {
+	maxGoroutinesSetting.Store(int64(maxGoroutines))
+	chunkRowsSetting.Store(int64(chunkRows))
+	logInfo("Concurrency set to at most %d goroutines, %d rows per chunk", maxGoroutines, chunkRows)
+}
+
+// currentChunkRows returns the rows per parallelRows chunk.
+func currentChunkRows() int {
+	if rows := chunkRowsSetting.Load(); rows > 0 {
+		return int(rows)
+	}
+	return CHUNK_SIZE
+}
+
+// goroutineLimit returns the goroutine limit set by setConcurrency, or fallback if none
+// has been set.
+func goroutineLimit(fallback int) int {
+	if limit := maxGoroutinesSetting.Load(); limit > 0 {
+		return int(limit)
+	}
+	return fallback
+}
+
+// initWorkerPoolWrapper exposes initWorkerPool to JavaScript.
+// It expects an optional worker count (default: the number of CPUs) and returns null or
+// an error object.
+func initWorkerPoolWrapper(this js.Value, args []js.Value) interface{} {
+	size := runtime.NumCPU()
+	if len(args) > 0 && !args[0].IsUndefined() {
+		if args[0].Type() != js.TypeNumber || args[0].Int() < 1 {
+			return createError("Invalid worker count: expected a positive number")
+		}
+		size = args[0].Int()
+	}
+	if err := initWorkerPool(size); err != nil {
+		return createError(err.Error())
+	}
+	return nil
+}
+
+// shutdownWorkerPoolWrapper exposes shutdownWorkerPool to JavaScript. Shutting down when
+// no pool is running is a no-op.
+func shutdownWorkerPoolWrapper(this js.Value, args []js.Value) interface{} {
+	shutdownWorkerPool()
+	return nil
+}
+
+// initWorkerPool starts `size` workers and routes parallelRows through them.
+func initWorkerPool(size int) error {
+	activeWorkerPoolMu.Lock()
+	defer activeWorkerPoolMu.Unlock()
+	if activeWorkerPool != nil {
+		return errors.New("Worker pool is already running; call shutdownWorkerPool first")
+	}
+
+	p := &workerPool{jobs: make(chan func(), size*4), quit: make(chan struct{}), size: size}
+	for i := 0; i < size; i++ {
+		go p.work()
+	}
+	activeWorkerPool = p
+	logInfo("Worker pool started with %d workers", size)
+	return nil
+}
+
+// shutdownWorkerPool stops the workers and returns parallelRows to per-call goroutines.
+// Calls already in flight still complete: their waiting goroutines run any jobs the
+// workers leave behind (see await).
+func shutdownWorkerPool() {
+	activeWorkerPoolMu.Lock()
+	p := activeWorkerPool
+	activeWorkerPool = nil
+	activeWorkerPoolMu.Unlock()
+
+	if p != nil {
+		close(p.quit)
+		logInfo("Worker pool stopped")
+	}
+}
+
+// currentWorkerPool returns the running pool, or nil.
+func currentWorkerPool() *workerPool {
+	activeWorkerPoolMu.Lock()
+	defer activeWorkerPoolMu.Unlock()
+	return activeWorkerPool
+}
+
+// work runs jobs until the pool is shut down.
+func (p *workerPool) work() {
+	for {
+		select {
+		case job := <-p.jobs:
+			job()
+		case <-p.quit:
+			return
+		}
+	}
+}
+
+// submit queues a job, running it on the calling goroutine instead when the queue is full
+// so that submission never blocks.
+func (p *workerPool) submit(job func()) {
+	select {
+	case p.jobs <- job:
+	default:
+		job()
+	}
+}
+
+// await blocks until n values arrive on done. While waiting, the caller runs queued jobs
+// itself, so a job that calls parallelRows (waiting on jobs of its own) cannot starve the
+// pool, and jobs still queued after shutdown are not stranded.
+func (p *workerPool) await(done <-chan bool, n int) {
+	for n > 0 {
+		select {
+		case <-done:
+			n--
+		case job := <-p.jobs:
+			job()
+		}
+	}
+}
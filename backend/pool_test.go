@@ -0,0 +1,157 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import (
+	"bytes"
+	"runtime"
+	"sync"
+	"syscall/js"
+	"testing"
+)
+
+// TestConcurrentCallsShareStateSafely interleaves calls that touch every piece of shared
+// state (the SVD and LUT caches, the concurrency settings, and the worker pool) from
+// several goroutines, as Web Workers sharing the module would, and checks each result
+// against the same call made alone. The race detector does not support js/wasm, so the
+// results themselves are what is checked.
+func TestConcurrentCallsShareStateSafely(t *testing.T) {
+	width, height := 24, 16
+	src := randomImage(width, height, 1)
+	edited := append([]uint8(nil), src...)
+	for y := 4; y < 8; y++ {
+		for x := 4; x < 10; x++ {
+			edited[(y*width+x)*4] = 255
+		}
+	}
+	imgJS := imageDataToJS(src, width, height)
+	editedJS := imageDataToJS(edited, width, height)
+	toneParams := map[string]interface{}{"brightness": 10, "contrast": 20, "gamma": 1.2}
+
+	wantBlur, err := applyFilter(src, width, height, "blur", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantTone, err := applyFilter(src, width, height, "tone", filterParams{"brightness": 10.0, "contrast": 20.0, "gamma": 1.2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache, err := cacheSVD(src, width, height, 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	updateSVDRegion(cache, edited, 4, 4, 6, 4)
+	wantSVD := reconstructCachedSVD(cache, cache.rank)
+
+	// One cache shared by every goroutine, updated with the same edit over and over
+	shared := cacheSVDWrapper(js.Undefined(), []js.Value{imgJS, js.ValueOf(6)})
+	defer freeSVDWrapper(js.Undefined(), []js.Value{js.ValueOf(shared)})
+	defer setConcurrency(0, 0)
+	defer shutdownWorkerPool()
+
+	const workers = 8
+	errs := make(chan string, workers*16)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 4; i++ {
+				setConcurrency(1+(w+i)%4, 1+(w*i)%9)
+				if w%4 == 0 {
+					if i%2 == 0 {
+						initWorkerPool(2) // Fails harmlessly when another goroutine's pool runs
+					} else {
+						shutdownWorkerPool()
+					}
+				}
+
+				got, err := applyFilter(src, width, height, "blur", nil)
+				if err != nil || !bytes.Equal(got, wantBlur) {
+					errs <- "applyFilter result differs"
+				}
+
+				handle := buildLUTWrapper(js.Undefined(), []js.Value{js.ValueOf(toneParams)})
+				result := applyLUTWrapper(js.Undefined(), []js.Value{js.ValueOf(handle), imgJS})
+				if data := jsBytes(result); !bytes.Equal(data, wantTone) {
+					errs <- "applyLUT result differs"
+				}
+				freeLUTWrapper(js.Undefined(), []js.Value{js.ValueOf(handle)})
+
+				own := cacheSVDWrapper(js.Undefined(), []js.Value{imgJS, js.ValueOf(6)})
+				for _, handle := range []interface{}{own, shared} {
+					result := updateSVDRegionWrapper(js.Undefined(), []js.Value{js.ValueOf(handle), editedJS,
+						js.ValueOf(4), js.ValueOf(4), js.ValueOf(6), js.ValueOf(4)})
+					if data := jsBytes(result); handle == own && !bytes.Equal(data, wantSVD) {
+						errs <- "updateSVDRegion result differs"
+					} else if data == nil {
+						errs <- "updateSVDRegion failed"
+					}
+				}
+				freeSVDWrapper(js.Undefined(), []js.Value{js.ValueOf(own)})
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(errs)
+	for msg := range errs {
+		t.Error(msg)
+	}
+}
+
+// jsBytes copies a Uint8ClampedArray result back into Go, or returns nil for an error
+// object.
+func jsBytes(result interface{}) []uint8 {
+	v, ok := result.(js.Value)
+	if !ok || v.Get("error").Type() == js.TypeString {
+		return nil
+	}
+	data := make([]uint8, v.Length())
+	js.CopyBytesToGo(data, v)
+	return data
+}
+
+func TestWorkerPoolOutputUnchanged(t *testing.T) {
+	width, height := 37, 300
+	src := randomImage(width, height, 2)
+	want, err := applyFilter(src, width, height, "sharpen", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := initWorkerPool(3); err != nil {
+		t.Fatal(err)
+	}
+	defer shutdownWorkerPool()
+	if err := initWorkerPool(3); err == nil {
+		t.Error("starting a second pool succeeded")
+	}
+	got, err := applyFilter(src, width, height, "sharpen", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Error("output differs with the worker pool running")
+	}
+}
+
+// BenchmarkSmallFilterCalls measures many small blur calls (a 16x512 image, 8 chunks each)
+// with per-call goroutines and with a persistent worker pool.
+func BenchmarkSmallFilterCalls(b *testing.B) {
+	width, height := 16, 512
+	src := randomImage(width, height, 1)
+	b.Run("goroutines", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			applyFilter(src, width, height, "blur", nil)
+		}
+	})
+	b.Run("pool", func(b *testing.B) {
+		if err := initWorkerPool(runtime.NumCPU()); err != nil {
+			b.Fatal(err)
+		}
+		defer shutdownWorkerPool()
+		for i := 0; i < b.N; i++ {
+			applyFilter(src, width, height, "blur", nil)
+		}
+	})
+}
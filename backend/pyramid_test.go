@@ -0,0 +1,42 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestLocalLaplacian(t *testing.T) {
+	width, height := 32, 24
+	// Gray texture of ±6 on a 50|200 edge; the filter works on luminance only
+	clean := splitImage(width, height, 16, [4]uint8{50, 50, 50, 255}, [4]uint8{200, 200, 200, 255})
+	noisy := append([]uint8(nil), clean...)
+	rng := rand.New(rand.NewSource(3))
+	for i := 0; i < len(noisy); i += 4 {
+		v := uint8(int(noisy[i]) + rng.Intn(13) - 6)
+		noisy[i], noisy[i+1], noisy[i+2] = v, v, v
+	}
+	run := func(amount float64) []uint8 {
+		t.Helper()
+		got, err := applyFilter(noisy, width, height, "local-laplacian", filterParams{"amount": amount})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return got
+	}
+
+	if diff := maxAbsDiff(run(1), noisy); diff > 1 {
+		t.Errorf("amount 1 changed the image by %d", diff)
+	}
+
+	// Small differences are detail: amplified above 1, smoothed below it
+	detail := columnError(noisy, clean, width, height, 0, 12)
+	if enhanced := columnError(run(2), clean, width, height, 0, 12); enhanced <= detail*1.2 {
+		t.Errorf("amount 2 took the texture from %.2f to %.2f, want it amplified", detail, enhanced)
+	}
+	if smoothed := columnError(run(0.5), clean, width, height, 0, 12); smoothed >= detail*0.8 {
+		t.Errorf("amount 0.5 took the texture from %.2f to %.2f, want it smoothed", detail, smoothed)
+	}
+}
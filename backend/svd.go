@@ -0,0 +1,510 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"syscall/js"
+	"time"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// compressSVDDecimatedWrapper wraps the compressSVDDecimated logic for syscall/js interaction.
+// It expects imageData { width, height, data: Uint8ClampedArray }, a rank, and an integer
+// decimation factor (>= 1).
+// It returns { data: Uint8ClampedArray, mse, rmse } or an error object.
+func compressSVDDecimatedWrapper(this js.Value, args []js.Value) interface{} {
+	startTime := time.Now()
+	logDebug("compressSVDDecimatedWrapper called")
+
+	if len(args) < 3 {
+		return createError("Invalid number of arguments for compressSVDDecimated: expected 3 (imageData, rank, factor)")
+	}
+	srcData, width, height, err := readImageData(args[0])
+	if err != nil {
+		return createError(err.Error())
+	}
+	if args[1].Type() != js.TypeNumber || args[1].Int() <= 0 {
+		return createError("Invalid rank argument: expected a positive number")
+	}
+	if args[2].Type() != js.TypeNumber || args[2].Int() < 1 {
+		return createError("Invalid factor argument: expected an integer >= 1")
+	}
+
+	resultData, mse := compressSVDDecimated(srcData, width, height, args[1].Int(), args[2].Int())
+
+	result := js.Global().Get("Object").New()
+	result.Set("data", bytesToJS(resultData))
+	result.Set("mse", mse)
+	result.Set("rmse", math.Sqrt(mse))
+
+	logInfo("compressSVDDecimatedWrapper completed in %v", time.Since(startTime))
+	return result
+}
+
+// compressSVDDecimated approximates the image at full resolution using a basis learned
+// from a decimated copy (internal logic). For each channel, the top-rank right singular
+// vectors of the downscaled matrix are linearly interpolated back to full width and
+// re-orthonormalized into a basis V; the full-resolution matrix M is then projected onto
+// it, M ≈ (M V) Vᵀ. Only the small matrix is factorized, so the cost is dominated by the
+// O(height * width * rank) projection rather than a full-size SVD.
+//
+// Unlike the preview path of compressSVD, every output pixel comes from the full-resolution
+// data, so smooth content is reproduced closely. Detail finer than the decimated grid has no
+// representation in the interpolated basis, so high-frequency images (text, fine texture)
+// lose that detail regardless of rank.
+//
+// Returns the reconstruction and its mean squared error over all four channels.
+func compressSVDDecimated(data []uint8, width, height, rank, factor int) ([]uint8, float64) {
+	small, smallWidth, smallHeight := downsampleBox(data, width, height, factor)
+	rank = min(rank, min(smallWidth, smallHeight))
+	logDebug("Decimated SVD: rank %d, %dx%d basis for %dx%d image", rank, smallWidth, smallHeight, width, height)
+
+	fullMatrices := imageToChannelMatrices(data, width, height)
+	smallMatrices := imageToChannelMatrices(small, smallWidth, smallHeight)
+
+	var reconstructed [4]*mat.Dense
+	done := make(chan bool, 4)
+	var panics panicTracker
+	for c := 0; c < 4; c++ {
+		go func(c int) {
+			defer func() {
+				if r := recover(); r != nil {
+					panics.record(r)
+				}
+				done <- true
+			}()
+
+			var svd mat.SVD
+			if !svd.Factorize(smallMatrices[c], mat.SVDThin) {
+				logError("SVD Factorization failed for a channel.")
+				reconstructed[c] = fullMatrices[c]
+				return
+			}
+			var v mat.Dense
+			svd.VTo(&v)
+
+			// Interpolate each basis vector from smallWidth samples to width samples
+			basis := mat.NewDense(width, rank, nil)
+			scale := float64(smallWidth) / float64(width)
+			for j := 0; j < rank; j++ {
+				for x := 0; x < width; x++ {
+					pos := clampFloat64((float64(x)+0.5)*scale-0.5, 0, float64(smallWidth-1))
+					x0 := int(pos)
+					x1 := min(x0+1, smallWidth-1)
+					t := pos - float64(x0)
+					basis.Set(x, j, v.At(x0, j)*(1-t)+v.At(x1, j)*t)
+				}
+			}
+			q, _ := orthonormalize(basis)
+
+			// Project the full-resolution channel onto the basis: M Q Qᵀ
+			var coeffs, result mat.Dense
+			coeffs.Mul(fullMatrices[c], q)
+			result.Mul(&coeffs, q.T())
+			reconstructed[c] = &result
+		}(c)
+	}
+	for c := 0; c < 4; c++ {
+		<-done
+	}
+	panics.repanic()
+
+	resultData := channelMatricesToImage(reconstructed, width, height)
+
+	sumSq := 0.0
+	for i := range data {
+		d := float64(resultData[i]) - float64(data[i])
+		sumSq += d * d
+	}
+	return resultData, sumSq / float64(len(data))
+}
+
+// compressSVDRegionWrapper wraps the compressSVDRegion logic for syscall/js interaction.
+// It expects imageData { width, height, data: Uint8ClampedArray }, a rank, and the
+// rectangle x, y, w, h to compress.
+// It returns the processed Uint8ClampedArray or an error object.
+func compressSVDRegionWrapper(this js.Value, args []js.Value) interface{} {
+	startTime := time.Now()
+	logDebug("compressSVDRegionWrapper called")
+
+	if len(args) < 6 {
+		return createError("Invalid number of arguments for compressSVDRegion: expected 6 (imageData, rank, x, y, w, h)")
+	}
+	srcData, width, height, err := readImageData(args[0])
+	if err != nil {
+		return createError(err.Error())
+	}
+	var values [5]int
+	for i := range values {
+		if args[1+i].Type() != js.TypeNumber {
+			return createError("Invalid arguments: rank, x, y, w, h must be numbers")
+		}
+		values[i] = args[1+i].Int()
+	}
+	rank, x, y, w, h := values[0], values[1], values[2], values[3], values[4]
+	if rank <= 0 {
+		return createError("Invalid rank argument: expected a positive number")
+	}
+	if w <= 0 || h <= 0 || x < 0 || y < 0 || x+w > width || y+h > height {
+		return createError(fmt.Sprintf("Invalid region (%d, %d, %d, %d) for a %dx%d image", x, y, w, h, width, height))
+	}
+
+	resultData := compressSVDRegion(srcData, width, height, rank, x, y, w, h)
+
+	logInfo("compressSVDRegionWrapper completed in %v", time.Since(startTime))
+	return bytesToJS(resultData)
+}
+
+// compressSVDRegion SVD-compresses only the rectangle (x, y, w, h) of the image (internal
+// logic). The region is extracted, each channel's submatrix is compressed with
+// compressMatrixSVD, and the result is written into a copy of the original, so every pixel
+// outside the rectangle is byte-identical to the input.
+func compressSVDRegion(data []uint8, width, height, rank, x, y, w, h int) []uint8 {
+	logDebug("Compressing region (%d, %d, %d, %d) at rank %d", x, y, w, h, rank)
+
+	region := cropImage(data, width, x, y, w, h)
+	matrices := imageToChannelMatrices(region, w, h)
+
+	var compressed [4]*mat.Dense
+	done := make(chan bool, 4)
+	var panics panicTracker
+	for c := 0; c < 4; c++ {
+		go func(c int) {
+			defer func() {
+				if r := recover(); r != nil {
+					panics.record(r)
+				}
+				done <- true
+			}()
+			compressed[c], _ = compressMatrixSVD(matrices[c], rank, 0)
+		}(c)
+	}
+	for c := 0; c < 4; c++ {
+		<-done
+	}
+	panics.repanic()
+
+	regionData := channelMatricesToImage(compressed, w, h)
+	resultData := make([]uint8, len(data))
+	copy(resultData, data)
+	for row := 0; row < h; row++ {
+		dst := ((y+row)*width + x) * 4
+		copy(resultData[dst:dst+w*4], regionData[row*w*4:(row+1)*w*4])
+	}
+	return resultData
+}
+
+// compressSVDAutoWrapper wraps the compressSVDAuto logic for syscall/js interaction.
+// It expects imageData { width, height, data: Uint8ClampedArray } and the fraction of
+// energy to keep, in (0, 1].
+// It returns { data: Uint8ClampedArray, ranks: [r, g, b, a], energy: [r, g, b, a] } or an
+// error object.
+func compressSVDAutoWrapper(this js.Value, args []js.Value) interface{} {
+	startTime := time.Now()
+	logDebug("compressSVDAutoWrapper called")
+
+	if len(args) < 2 {
+		return createError("Invalid number of arguments for compressSVDAuto: expected 2 (imageData, energy)")
+	}
+	srcData, width, height, err := readImageData(args[0])
+	if err != nil {
+		return createError(err.Error())
+	}
+	if args[1].Type() != js.TypeNumber || !(args[1].Float() > 0 && args[1].Float() <= 1) {
+		return createError("Invalid energy argument: expected a number in (0, 1]")
+	}
+
+	resultData, ranks, energy := compressSVDAuto(srcData, width, height, args[1].Float())
+
+	result := js.Global().Get("Object").New()
+	result.Set("data", bytesToJS(resultData))
+	result.Set("ranks", []interface{}{ranks[0], ranks[1], ranks[2], ranks[3]})
+	result.Set("energy", []interface{}{energy[0], energy[1], energy[2], energy[3]})
+
+	logInfo("compressSVDAutoWrapper completed in %v", time.Since(startTime))
+	return result
+}
+
+// compressSVDAuto SVD-compresses each channel at the smallest rank that keeps `fraction` of
+// its energy (internal logic). Every channel is fully factorized, its rank chosen with
+// energyRank, and it is reconstructed from those leading triplets, so channels with simple
+// content (often alpha) end up at much lower ranks than detailed ones. fraction 1 keeps
+// every non-zero triplet and reproduces the image exactly.
+// Returns the reconstruction, the rank chosen per channel, and the energy each retains.
+func compressSVDAuto(data []uint8, width, height int, fraction float64) ([]uint8, [4]int, [4]float64) {
+	logDebug("Compressing SVD keeping %.4f of the energy, dimensions %dx%d", fraction, width, height)
+	matrices := imageToChannelMatrices(data, width, height)
+
+	var compressed [4]*mat.Dense
+	var ranks [4]int
+	var energy [4]float64
+	done := make(chan bool, 4)
+	var panics panicTracker
+	for c := 0; c < 4; c++ {
+		go func(c int) {
+			defer func() {
+				if r := recover(); r != nil {
+					panics.record(r)
+				}
+				done <- true
+			}()
+			f, err := factorizeTruncated(matrices[c], min(width, height))
+			if err != nil {
+				logError("compressSVDAuto: %v; keeping channel %d unchanged", err, c)
+				compressed[c], ranks[c], energy[c] = matrices[c], min(width, height), 1
+				return
+			}
+			ranks[c] = energyRank(f.S, fraction)
+			if ranks[c] == 0 {
+				compressed[c] = mat.NewDense(height, width, nil)
+			} else {
+				compressed[c] = f.reconstruct(ranks[c])
+			}
+			energy[c] = retainedEnergy(matrices[c], f.S[:ranks[c]])
+		}(c)
+	}
+	for c := 0; c < 4; c++ {
+		<-done
+	}
+	panics.repanic()
+	logDebug("compressSVDAuto chose ranks %v", ranks)
+
+	return channelMatricesToImage(compressed, width, height), ranks, energy
+}
+
+// energyRank returns the smallest k whose leading squared singular values sum to at least
+// fraction of the total. It is 0 for an all-zero channel and never exceeds len(values), so
+// fraction 1 cannot be missed through rounding in the running sum.
+func energyRank(values []float64, fraction float64) int {
+	total := 0.0
+	for _, sigma := range values {
+		total += sigma * sigma
+	}
+	if total == 0 {
+		return 0
+	}
+	k, sum := 0, 0.0
+	for k < len(values) && sum < fraction*total {
+		sum += values[k] * values[k]
+		k++
+	}
+	return k
+}
+
+// compressSVDGrayWrapper wraps the compressSVDGray logic for syscall/js interaction.
+// It expects imageData { width, height, data: Uint8ClampedArray } and a rank.
+// It returns { data: Uint8ClampedArray, stats } (see svdStats.toJS) or an error object.
+func compressSVDGrayWrapper(this js.Value, args []js.Value) interface{} {
+	startTime := time.Now()
+	logDebug("compressSVDGrayWrapper called")
+
+	if len(args) < 2 {
+		return createError("Invalid number of arguments for compressSVDGray: expected 2 (imageData, rank)")
+	}
+	srcData, width, height, err := readImageData(args[0])
+	if err != nil {
+		return createError(err.Error())
+	}
+	if args[1].Type() != js.TypeNumber || args[1].Int() <= 0 {
+		return createError("Invalid rank argument: expected a positive number")
+	}
+
+	resultData, stats := compressSVDGray(srcData, width, height, args[1].Int())
+
+	result := js.Global().Get("Object").New()
+	result.Set("data", bytesToJS(resultData))
+	result.Set("stats", stats.toJS())
+
+	logInfo("compressSVDGrayWrapper completed in %v", time.Since(startTime))
+	return result
+}
+
+// compressSVDGray SVD-compresses the image's Rec.601 luminance as a single matrix and writes
+// the reconstruction to all three color channels (internal logic). It does one
+// factorization instead of compressSVD's four, at the cost of all color, so it suits
+// grayscale and near-grayscale content. Alpha is copied unchanged, so the
+// stats report it at rank 0 with all of its energy. The rank is clamped like compressSVD's,
+// and the storage ratio counts the single luminance matrix.
+func compressSVDGray(data []uint8, width, height, rank int) ([]uint8, svdStats) {
+	logDebug("Compressing grayscale SVD: rank %d, dimensions %dx%d", rank, width, height)
+	luma := mat.NewDense(height, width, lumaPlane(data, width, height))
+
+	stats := svdStats{Energy: [4]float64{1, 1, 1, 1}, StorageRatio: 1}
+	reconstructed := luma
+	if maxRank := maxUsefulSVDRank(width, height); maxRank > 0 {
+		rank = min(rank, maxRank)
+		var kept []float64
+		reconstructed, kept = compressMatrixSVD(luma, rank, 0)
+		energy := retainedEnergy(luma, kept)
+		stats.Ranks = [4]int{rank, rank, rank, 0}
+		stats.Energy = [4]float64{energy, energy, energy, 1}
+		stats.StorageRatio = float64(rank*(width+height+1)) / float64(width*height)
+	} else {
+		full := min(width, height)
+		stats.Ranks = [4]int{full, full, full, 0}
+	}
+
+	resultData := make([]uint8, len(data))
+	parallelRows(height, func(startY, endY int) {
+		for y := startY; y < endY; y++ {
+			for x := 0; x < width; x++ {
+				idx := (y*width + x) * 4
+				v := uint8(clampFloat64(reconstructed.At(y, x)+0.5, 0, 255))
+				resultData[idx], resultData[idx+1], resultData[idx+2] = v, v, v
+				resultData[idx+3] = data[idx+3]
+			}
+		}
+	})
+	return resultData, stats
+}
+
+// randomizedPowerIterations is the number of subspace iterations compressMatrixSVDRandomized
+// runs after the initial projection. Image channels have slowly decaying spectra, so the
+// plain projection mixes in too much of the tail; one iteration sharpens it to within a
+// fraction of a percent of the exact error.
+const randomizedPowerIterations = 1
+
+// compressMatrixSVDRandomized approximates compressMatrixSVD with a randomized SVD (Halko,
+// Martinsson and Tropp, "Finding structure with randomness", 2011). m is multiplied by a
+// Gaussian random matrix with rank+oversampling columns, and the orthonormalized product Q
+// captures m's dominant column space. Q is refined by randomizedPowerIterations rounds of
+// multiplying by mᵀ and m, after which only the small matrix Qᵀm is factorized exactly;
+// its top singular triplets, with the left vectors mapped back through Q, give the
+// approximation. The cost is a handful of (rows x cols x (rank+oversampling)) products
+// instead of a full factorization: about 10x faster than compressMatrixSVD for a 512x512
+// matrix at rank 20 with oversampling 10, with a reconstruction error within 2% of the
+// exact one (see BenchmarkRandomizedSVD).
+//
+// The fraction behaves as in compressMatrixSVD. The random matrix comes from seed, so equal
+// inputs always give equal results. It returns the singular values used, like
+// compressMatrixSVD.
+func compressMatrixSVDRandomized(m *mat.Dense, rank int, fraction float64, oversampling int, seed int64) (*mat.Dense, []float64) {
+	rows, cols := m.Dims()
+	keep := rank
+	if fraction > 0 {
+		keep++
+	}
+	samples := min(keep+oversampling, min(rows, cols))
+	effectiveRank := min(keep, samples)
+	if effectiveRank <= 0 {
+		logError("compressMatrixSVDRandomized: Invalid rank, returning original.")
+		return m, nil
+	}
+
+	rng := rand.New(rand.NewSource(seed))
+	omega := mat.NewDense(cols, samples, nil)
+	for i := 0; i < cols; i++ {
+		for j := 0; j < samples; j++ {
+			omega.Set(i, j, rng.NormFloat64())
+		}
+	}
+	var y, z mat.Dense
+	y.Mul(m, omega)
+	q, _ := orthonormalize(&y)
+	for iter := 0; iter < randomizedPowerIterations; iter++ {
+		z.Mul(m.T(), q)
+		w, _ := orthonormalize(&z)
+		y.Mul(m, w)
+		q, _ = orthonormalize(&y)
+	}
+
+	// B = Qᵀm is only samples x cols
+	var b mat.Dense
+	b.Mul(q.T(), m)
+	var svd mat.SVD
+	if !svd.Factorize(&b, mat.SVDThin) {
+		logError("SVD Factorization failed for a channel.")
+		return m, nil
+	}
+	var ub, v mat.Dense
+	svd.UTo(&ub)
+	svd.VTo(&v)
+	s := svd.Values(nil)
+
+	sr := mat.NewDiagDense(effectiveRank, s[:effectiveRank])
+	if fraction > 0 && rank > 0 && effectiveRank == keep {
+		sr.SetDiag(keep-1, s[keep-1]*fraction)
+	}
+
+	// result = Q * (Ub_r * S_r * V_rᵀ), keeping the large products at samples columns
+	var temp, proj, result mat.Dense
+	temp.Mul(ub.Slice(0, samples, 0, effectiveRank), sr)
+	proj.Mul(&temp, v.Slice(0, cols, 0, effectiveRank).T())
+	result.Mul(q, &proj)
+	return &result, sr.RawBand().Data
+}
+
+// analyzeSVDWrapper wraps the analyzeSVD logic for syscall/js interaction.
+// It expects imageData { width, height, data: Uint8ClampedArray }.
+// It returns { r, g, b, a }, each a Float64Array of that channel's singular values in
+// descending order, or an error object.
+func analyzeSVDWrapper(this js.Value, args []js.Value) interface{} {
+	startTime := time.Now()
+	logDebug("analyzeSVDWrapper called")
+
+	if len(args) < 1 {
+		return createError("Invalid number of arguments for analyzeSVD: expected 1 (imageData)")
+	}
+	srcData, width, height, err := readImageData(args[0])
+	if err != nil {
+		return createError(err.Error())
+	}
+
+	values, err := analyzeSVD(srcData, width, height)
+	if err != nil {
+		return createError(err.Error())
+	}
+
+	result := js.Global().Get("Object").New()
+	for c, name := range []string{"r", "g", "b", "a"} {
+		result.Set(name, float64sToJS(values[c]))
+	}
+
+	logInfo("analyzeSVDWrapper completed in %v", time.Since(startTime))
+	return result
+}
+
+// analyzeSVD returns the min(width, height) singular values of each channel (R, G, B, A),
+// largest first (internal logic), for plotting how much each rank contributes. The channels
+// are factorized in parallel with mat.SVDNone, which computes the values without U and V
+// and so skips both their storage and any reconstruction. A 1-pixel image has the single
+// singular value |v| per channel.
+func analyzeSVD(data []uint8, width, height int) ([4][]float64, error) {
+	matrices := imageToChannelMatrices(data, width, height)
+	var values [4][]float64
+	var failed [4]bool
+	done := make(chan bool, 4)
+	var panics panicTracker
+	for c := 0; c < 4; c++ {
+		go func(c int) {
+			defer func() {
+				if r := recover(); r != nil {
+					panics.record(r)
+				}
+				done <- true
+			}()
+			var svd mat.SVD
+			if !svd.Factorize(matrices[c], mat.SVDNone) {
+				failed[c] = true
+				return
+			}
+			values[c] = svd.Values(nil)
+		}(c)
+	}
+	for c := 0; c < 4; c++ {
+		<-done
+	}
+	panics.repanic()
+
+	for c := range failed {
+		if failed[c] {
+			return values, fmt.Errorf("SVD factorization failed for channel %d", c)
+		}
+	}
+	return values, nil
+}
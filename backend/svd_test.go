@@ -0,0 +1,106 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import (
+	"math"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// reconstructionError returns the Frobenius norm of m - approx.
+func reconstructionError(m, approx *mat.Dense) float64 {
+	var diff mat.Dense
+	diff.Sub(m, approx)
+	return mat.Norm(&diff, 2)
+}
+
+func TestRandomizedSVDNearExact(t *testing.T) {
+	m := channelMatrix(120, 150, 1)
+	exact, _ := compressMatrixSVD(m, 10, 0)
+	approx, kept := compressMatrixSVDRandomized(m, 10, 0, 10, 42)
+	if len(kept) != 10 {
+		t.Fatalf("kept %d singular values, want 10", len(kept))
+	}
+	exactErr, approxErr := reconstructionError(m, exact), reconstructionError(m, approx)
+	if approxErr > exactErr*1.02 {
+		t.Errorf("randomized error %v exceeds the exact error %v by more than 2%%", approxErr, exactErr)
+	}
+
+	again, _ := compressMatrixSVDRandomized(m, 10, 0, 10, 42)
+	if !mat.Equal(approx, again) {
+		t.Error("equal seeds gave different reconstructions")
+	}
+}
+
+// BenchmarkRandomizedSVD compares the randomized and exact SVD on a 512x512 channel at rank
+// 20, reporting the randomized error relative to the exact one as relErr.
+func BenchmarkRandomizedSVD(b *testing.B) {
+	m := channelMatrix(512, 512, 1)
+	exact, _ := compressMatrixSVD(m, 20, 0)
+	exactErr := reconstructionError(m, exact)
+	b.Run("randomized", func(b *testing.B) {
+		var approx *mat.Dense
+		for i := 0; i < b.N; i++ {
+			approx, _ = compressMatrixSVDRandomized(m, 20, 0, 10, 1)
+		}
+		b.ReportMetric(reconstructionError(m, approx)/exactErr, "relErr")
+	})
+	b.Run("exact", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			compressMatrixSVD(m, 20, 0)
+		}
+	})
+}
+
+func TestCompressSVDDecimated(t *testing.T) {
+	width, height := 96, 64
+	smooth := smoothImage(width, height)
+	got, mse := compressSVDDecimated(smooth, width, height, 8, 4)
+	if len(got) != len(smooth) {
+		t.Fatalf("got %d bytes, want %d", len(got), len(smooth))
+	}
+	sumSq := 0.0
+	for i := range got {
+		d := float64(got[i]) - float64(smooth[i])
+		sumSq += d * d
+	}
+	if want := sumSq / float64(len(got)); math.Abs(mse-want) > 1e-9 {
+		t.Errorf("reported MSE %v, want %v", mse, want)
+	}
+	if rmse := math.Sqrt(mse); rmse > 3 {
+		t.Errorf("smooth gradient RMSE %.2f, want at most 3", rmse)
+	}
+
+	// Detail finer than the decimated grid is lost, whatever the rank
+	_, noiseMSE := compressSVDDecimated(randomImage(width, height, 1), width, height, 8, 4)
+	if noiseMSE <= mse {
+		t.Errorf("noise MSE %v is not above the smooth image's %v", noiseMSE, mse)
+	}
+	t.Logf("RMSE: smooth %.2f, noise %.2f", math.Sqrt(mse), math.Sqrt(noiseMSE))
+}
+
+func TestCompressSVDRegion(t *testing.T) {
+	width, height := 20, 16
+	src := randomImage(width, height, 2)
+	x, y, w, h := 4, 3, 10, 8
+	got := compressSVDRegion(src, width, height, 1, x, y, w, h)
+
+	changed := false
+	for py := 0; py < height; py++ {
+		for px := 0; px < width; px++ {
+			idx := (py*width + px) * 4
+			inside := px >= x && px < x+w && py >= y && py < y+h
+			if same := maxAbsDiff(got[idx:idx+4], src[idx:idx+4]) == 0; !inside && !same {
+				t.Fatalf("(%d, %d) outside the region changed", px, py)
+			} else if inside && !same {
+				changed = true
+			}
+		}
+	}
+	if !changed {
+		t.Error("rank 1 left the random region unchanged")
+	}
+}
@@ -0,0 +1,448 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"syscall/js"
+	"time"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// channelSVD is a truncated factorization U * diag(S) * Vᵀ of one channel matrix.
+type channelSVD struct {
+	U *mat.Dense // rows x rank
+	S []float64  // rank singular values, descending
+	V *mat.Dense // cols x rank
+}
+
+// cachedSVD keeps the factorizations of all four channels of an image together with the
+// exact pixels they were derived from, so later edits can be applied as low-rank updates.
+// The dimensions and rank are fixed at creation; mu guards data and channels, which
+// updateSVDRegion replaces.
+type cachedSVD struct {
+	width, height int
+	rank          int
+	mu            sync.Mutex
+	data          []uint8
+	channels      [4]channelSVD
+}
+
+// svdCache holds the factorizations created by cacheSVD, keyed by handle. svdCacheMu
+// guards the map and nextSVDHandle.
+var (
+	svdCacheMu    sync.Mutex
+	svdCache      = map[int]*cachedSVD{}
+	nextSVDHandle = 1
+)
+
+// cacheSVDWrapper wraps the cacheSVD logic for syscall/js interaction.
+// It expects imageData { width, height, data: Uint8ClampedArray } and the rank to retain.
+// It returns a numeric handle for use with updateSVDRegion, reconstructCachedSVD, and
+// freeSVD, or an error object.
+func cacheSVDWrapper(this js.Value, args []js.Value) interface{} {
+	startTime := time.Now()
+	logDebug("cacheSVDWrapper called")
+
+	if len(args) < 2 {
+		return createError("Invalid number of arguments for cacheSVD: expected 2 (imageData, rank)")
+	}
+	srcData, width, height, err := readImageData(args[0])
+	if err != nil {
+		return createError(err.Error())
+	}
+	if args[1].Type() != js.TypeNumber || args[1].Int() <= 0 {
+		return createError("Invalid rank argument: expected a positive number")
+	}
+
+	cache, err := cacheSVD(srcData, width, height, args[1].Int())
+	if err != nil {
+		return createError(err.Error())
+	}
+	svdCacheMu.Lock()
+	handle := nextSVDHandle
+	nextSVDHandle++
+	svdCache[handle] = cache
+	svdCacheMu.Unlock()
+
+	logInfo("cacheSVDWrapper completed in %v (handle %d)", time.Since(startTime), handle)
+	return handle
+}
+
+// updateSVDRegionWrapper wraps the updateSVDRegion logic for syscall/js interaction.
+// It expects a cache handle, the edited imageData (same dimensions as the cached image),
+// and the edited rectangle x, y, w, h. Pixels outside the rectangle are assumed unchanged.
+// It returns the recompressed Uint8ClampedArray at the cached rank or an error object.
+func updateSVDRegionWrapper(this js.Value, args []js.Value) interface{} {
+	startTime := time.Now()
+	logDebug("updateSVDRegionWrapper called")
+
+	if len(args) < 6 {
+		return createError("Invalid number of arguments for updateSVDRegion: expected 6 (handle, imageData, x, y, w, h)")
+	}
+	cache, err := lookupSVDCache(args[0])
+	if err != nil {
+		return createError(err.Error())
+	}
+	srcData, width, height, err := readImageData(args[1])
+	if err != nil {
+		return createError(err.Error())
+	}
+	if width != cache.width || height != cache.height {
+		return createError(fmt.Sprintf("Image dimensions %dx%d do not match the cached %dx%d", width, height, cache.width, cache.height))
+	}
+	var rect [4]int
+	for i := range rect {
+		if args[2+i].Type() != js.TypeNumber {
+			return createError("Invalid region: x, y, w, h must be numbers")
+		}
+		rect[i] = args[2+i].Int()
+	}
+	x, y, w, h := rect[0], rect[1], rect[2], rect[3]
+	if w <= 0 || h <= 0 || x < 0 || y < 0 || x+w > width || y+h > height {
+		return createError(fmt.Sprintf("Invalid region (%d, %d, %d, %d) for a %dx%d image", x, y, w, h, width, height))
+	}
+
+	cache.mu.Lock()
+	updateSVDRegion(cache, srcData, x, y, w, h)
+	resultData := reconstructCachedSVD(cache, cache.rank)
+	cache.mu.Unlock()
+
+	logInfo("updateSVDRegionWrapper completed in %v", time.Since(startTime))
+	return bytesToJS(resultData)
+}
+
+// reconstructCachedSVDWrapper rebuilds the image from a cached factorization.
+// It expects a cache handle and a rank (at most the cached rank).
+// It returns the reconstructed Uint8ClampedArray or an error object.
+func reconstructCachedSVDWrapper(this js.Value, args []js.Value) interface{} {
+	if len(args) < 2 {
+		return createError("Invalid number of arguments for reconstructCachedSVD: expected 2 (handle, rank)")
+	}
+	cache, err := lookupSVDCache(args[0])
+	if err != nil {
+		return createError(err.Error())
+	}
+	if args[1].Type() != js.TypeNumber || args[1].Int() <= 0 || args[1].Int() > cache.rank {
+		return createError(fmt.Sprintf("Invalid rank argument: expected a number between 1 and the cached rank %d", cache.rank))
+	}
+	cache.mu.Lock()
+	resultData := reconstructCachedSVD(cache, args[1].Int())
+	cache.mu.Unlock()
+	return bytesToJS(resultData)
+}
+
+// freeSVDWrapper releases a cached factorization. Freeing an unknown handle is a no-op.
+func freeSVDWrapper(this js.Value, args []js.Value) interface{} {
+	if len(args) < 1 || args[0].Type() != js.TypeNumber {
+		return createError("Invalid arguments for freeSVD: expected 1 (handle)")
+	}
+	svdCacheMu.Lock()
+	delete(svdCache, args[0].Int())
+	svdCacheMu.Unlock()
+	return nil
+}
+
+// lookupSVDCache resolves a JS handle to its cached factorization.
+func lookupSVDCache(handleJS js.Value) (*cachedSVD, error) {
+	if handleJS.Type() != js.TypeNumber {
+		return nil, errors.New("Invalid handle: expected a number")
+	}
+	svdCacheMu.Lock()
+	cache, ok := svdCache[handleJS.Int()]
+	svdCacheMu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("Unknown SVD handle %d (already freed?)", handleJS.Int())
+	}
+	return cache, nil
+}
+
+// imageToChannelMatrices splits RGBA pixel data into one height x width matrix per channel.
+func imageToChannelMatrices(data []uint8, width, height int) [4]*mat.Dense {
+	var channels [4]*mat.Dense
+	for c := range channels {
+		channels[c] = mat.NewDense(height, width, nil)
+	}
+	parallelRows(height, func(startY, endY int) {
+		for y := startY; y < endY; y++ {
+			for x := 0; x < width; x++ {
+				idx := (y*width + x) * 4
+				for c := 0; c < 4; c++ {
+					channels[c].Set(y, x, float64(data[idx+c]))
+				}
+			}
+		}
+	})
+	return channels
+}
+
+// channelMatricesToImage rounds and clamps four channel matrices back into RGBA pixel data.
+func channelMatricesToImage(channels [4]*mat.Dense, width, height int) []uint8 {
+	data := make([]uint8, width*height*4)
+	parallelRows(height, func(startY, endY int) {
+		for y := startY; y < endY; y++ {
+			for x := 0; x < width; x++ {
+				idx := (y*width + x) * 4
+				for c := 0; c < 4; c++ {
+					data[idx+c] = uint8(clampFloat64(channels[c].At(y, x)+0.5, 0, 255))
+				}
+			}
+		}
+	})
+	return data
+}
+
+// factorizeTruncated computes the SVD of m and keeps only the leading rank triplets.
+func factorizeTruncated(m *mat.Dense, rank int) (channelSVD, error) {
+	rows, cols := m.Dims()
+	rank = min(rank, min(rows, cols))
+
+	var svd mat.SVD
+	if !svd.Factorize(m, mat.SVDThin) {
+		return channelSVD{}, errors.New("SVD factorization failed")
+	}
+	var u, v mat.Dense
+	svd.UTo(&u)
+	svd.VTo(&v)
+	s := svd.Values(nil)
+
+	return channelSVD{
+		U: mat.DenseCopyOf(u.Slice(0, rows, 0, rank)),
+		S: s[:rank],
+		V: mat.DenseCopyOf(v.Slice(0, cols, 0, rank)),
+	}, nil
+}
+
+// reconstruct returns U_k * diag(S_k) * V_kᵀ using the leading k triplets.
+func (f channelSVD) reconstruct(k int) *mat.Dense {
+	rows, _ := f.U.Dims()
+	cols, _ := f.V.Dims()
+	us := mat.DenseCopyOf(f.U.Slice(0, rows, 0, k))
+	for j := 0; j < k; j++ {
+		for i := 0; i < rows; i++ {
+			us.Set(i, j, us.At(i, j)*f.S[j])
+		}
+	}
+	var result mat.Dense
+	result.Mul(us, f.V.Slice(0, cols, 0, k).T())
+	return &result
+}
+
+// cacheSVD factorizes all four channels of an image, retaining the top rank triplets
+// (internal logic). The four factorizations run in parallel like compressSVD's.
+func cacheSVD(data []uint8, width, height, rank int) (*cachedSVD, error) {
+	logDebug("Caching SVD: rank %d, dimensions %dx%d", rank, width, height)
+	matrices := imageToChannelMatrices(data, width, height)
+
+	cache := &cachedSVD{width: width, height: height, data: make([]uint8, len(data))}
+	copy(cache.data, data)
+
+	errs := make(chan error, 4)
+	var panics panicTracker
+	for c := 0; c < 4; c++ {
+		go func(c int) {
+			var err error
+			defer func() {
+				if r := recover(); r != nil {
+					panics.record(r)
+				}
+				errs <- err
+			}()
+			cache.channels[c], err = factorizeTruncated(matrices[c], rank)
+		}(c)
+	}
+	var firstErr error
+	for c := 0; c < 4; c++ {
+		if err := <-errs; err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	panics.repanic()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	cache.rank = len(cache.channels[0].S)
+	return cache, nil
+}
+
+// reconstructCachedSVD rebuilds RGBA pixel data from the cache at rank k (internal logic).
+func reconstructCachedSVD(cache *cachedSVD, k int) []uint8 {
+	var channels [4]*mat.Dense
+	for c := range channels {
+		channels[c] = cache.channels[c].reconstruct(k)
+	}
+	return channelMatricesToImage(channels, cache.width, cache.height)
+}
+
+// updateSVDRegion folds an edit confined to the rectangle (x, y, w, h) into the cached
+// factorizations (internal logic). The change D = new - old is zero outside the rectangle,
+// so it factors as D = A * Bᵀ with only min(w, h) columns, and each channel's truncated
+// SVD is updated with Brand's rank-k update rather than refactorizing the whole matrix.
+// Because the cache is truncated, the result is the best rank-r approximation within the
+// span of the old basis plus the edit, which closely tracks a fresh truncated SVD.
+func updateSVDRegion(cache *cachedSVD, newData []uint8, x, y, w, h int) {
+	logDebug("Updating cached SVD for region (%d, %d, %d, %d)", x, y, w, h)
+	width, height := cache.width, cache.height
+	k := min(w, h)
+
+	done := make(chan bool, 4)
+	var panics panicTracker
+	for c := 0; c < 4; c++ {
+		go func(c int) {
+			defer func() {
+				if r := recover(); r != nil {
+					panics.record(r)
+				}
+				done <- true
+			}()
+
+			// D = P * block * Qᵀ where P/Q select the region's rows/columns. Put the block on
+			// whichever side keeps the inner dimension at min(w, h).
+			a := mat.NewDense(height, k, nil)
+			b := mat.NewDense(width, k, nil)
+			for ry := 0; ry < h; ry++ {
+				for rx := 0; rx < w; rx++ {
+					idx := ((y+ry)*width + (x + rx)) * 4
+					d := float64(newData[idx+c]) - float64(cache.data[idx+c])
+					if w <= h {
+						a.Set(y+ry, rx, d) // A = P * block, B = Q
+					} else {
+						b.Set(x+rx, ry, d) // A = P, B = Q * blockᵀ
+					}
+				}
+			}
+			for i := 0; i < k; i++ {
+				if w <= h {
+					b.Set(x+i, i, 1)
+				} else {
+					a.Set(y+i, i, 1)
+				}
+			}
+
+			cache.channels[c] = brandUpdate(cache.channels[c], a, b)
+		}(c)
+	}
+	for c := 0; c < 4; c++ {
+		<-done
+	}
+	panics.repanic()
+
+	// Keep the exact pixels in sync so later edits produce correct deltas
+	for ry := 0; ry < h; ry++ {
+		start := ((y+ry)*width + x) * 4
+		copy(cache.data[start:start+w*4], newData[start:start+w*4])
+	}
+}
+
+// brandUpdate returns the rank-r truncated SVD of U*diag(S)*Vᵀ + A*Bᵀ, where r is the
+// current rank (Brand, "Fast low-rank modifications of the thin SVD", 2006).
+//
+// With P, Ra from orthonormalizing (I - UUᵀ)A and Q, Rb from (I - VVᵀ)B,
+//
+//	U S Vᵀ + A Bᵀ = [U P] K [V Q]ᵀ,  K = [S 0; 0 0] + [UᵀA; Ra] [VᵀB; Rb]ᵀ
+//
+// so only the small (r+k) x (r+k) matrix K has to be factorized.
+func brandUpdate(f channelSVD, a, b *mat.Dense) channelSVD {
+	rows, r := f.U.Dims()
+	cols, _ := f.V.Dims()
+	_, k := a.Dims()
+
+	var uta, vtb mat.Dense
+	uta.Mul(f.U.T(), a) // r x k
+	vtb.Mul(f.V.T(), b) // r x k
+
+	var residualA, residualB mat.Dense
+	residualA.Mul(f.U, &uta)
+	residualA.Sub(a, &residualA)
+	residualB.Mul(f.V, &vtb)
+	residualB.Sub(b, &residualB)
+	p, ra := orthonormalize(&residualA)
+	q, rb := orthonormalize(&residualB)
+
+	// Left and right factors of the update expressed in the extended bases
+	left := mat.NewDense(r+k, k, nil)
+	left.Slice(0, r, 0, k).(*mat.Dense).Copy(&uta)
+	left.Slice(r, r+k, 0, k).(*mat.Dense).Copy(ra)
+	right := mat.NewDense(r+k, k, nil)
+	right.Slice(0, r, 0, k).(*mat.Dense).Copy(&vtb)
+	right.Slice(r, r+k, 0, k).(*mat.Dense).Copy(rb)
+
+	var kMat mat.Dense
+	kMat.Mul(left, right.T())
+	for i := 0; i < r; i++ {
+		kMat.Set(i, i, kMat.At(i, i)+f.S[i])
+	}
+
+	var svd mat.SVD
+	if !svd.Factorize(&kMat, mat.SVDThin) {
+		logError("SVD update failed for a channel; keeping the previous factorization.")
+		return f
+	}
+	var uk, vk mat.Dense
+	svd.UTo(&uk)
+	svd.VTo(&vk)
+	s := svd.Values(nil)
+
+	// Rotate the extended bases and truncate back to rank r
+	uExt := mat.NewDense(rows, r+k, nil)
+	uExt.Slice(0, rows, 0, r).(*mat.Dense).Copy(f.U)
+	uExt.Slice(0, rows, r, r+k).(*mat.Dense).Copy(p)
+	vExt := mat.NewDense(cols, r+k, nil)
+	vExt.Slice(0, cols, 0, r).(*mat.Dense).Copy(f.V)
+	vExt.Slice(0, cols, r, r+k).(*mat.Dense).Copy(q)
+
+	var uNew, vNew mat.Dense
+	uNew.Mul(uExt, uk.Slice(0, r+k, 0, r))
+	vNew.Mul(vExt, vk.Slice(0, r+k, 0, r))
+	return channelSVD{U: &uNew, S: s[:r], V: &vNew}
+}
+
+// orthonormalize factors m (n x k) as Q * R with orthonormal (or zero) columns in Q and
+// upper-triangular R, using modified Gram-Schmidt with one reorthogonalization pass.
+// Columns that are numerically dependent on earlier ones become zero in Q.
+func orthonormalize(m *mat.Dense) (*mat.Dense, *mat.Dense) {
+	n, k := m.Dims()
+	q := mat.DenseCopyOf(m)
+	rMat := mat.NewDense(k, k, nil)
+	scale := mat.Norm(m, 2) + 1
+
+	col := make([]float64, n)
+	for j := 0; j < k; j++ {
+		mat.Col(col, j, q)
+		for pass := 0; pass < 2; pass++ {
+			for i := 0; i < j; i++ {
+				dot := 0.0
+				for t := 0; t < n; t++ {
+					dot += q.At(t, i) * col[t]
+				}
+				for t := 0; t < n; t++ {
+					col[t] -= dot * q.At(t, i)
+				}
+				rMat.Set(i, j, rMat.At(i, j)+dot)
+			}
+		}
+
+		norm := 0.0
+		for _, v := range col {
+			norm += v * v
+		}
+		norm = math.Sqrt(norm)
+		if norm <= 1e-10*scale {
+			norm = 0
+		}
+		rMat.Set(j, j, norm)
+		for t := 0; t < n; t++ {
+			if norm == 0 {
+				q.Set(t, j, 0)
+			} else {
+				q.Set(t, j, col[t]/norm)
+			}
+		}
+	}
+	return q, rMat
+}
@@ -0,0 +1,49 @@
+//go:build js && wasm
+// +build js,wasm
+
+package main
+
+import "testing"
+
+// meanAbsDiff returns the mean per-byte difference between a and b.
+func meanAbsDiff(a, b []uint8) float64 {
+	sum := 0
+	for i := range a {
+		sum += abs(int(a[i]) - int(b[i]))
+	}
+	return float64(sum) / float64(len(a))
+}
+
+func TestUpdateSVDRegionMatchesFreshSVD(t *testing.T) {
+	width, height, rank := 48, 40, 8
+	src := smoothImage(width, height)
+	edited := append([]uint8(nil), src...)
+	for y := 10; y < 16; y++ {
+		for x := 20; x < 28; x++ {
+			copy(edited[(y*width+x)*4:], []uint8{250, 10, 60, 255})
+		}
+	}
+
+	cache := mustCacheSVD(t, src, width, height, rank)
+	updateSVDRegion(cache, edited, 20, 10, 8, 6)
+	updated := reconstructCachedSVD(cache, rank)
+
+	want := reconstructCachedSVD(mustCacheSVD(t, edited, width, height, rank), rank)
+
+	if diff := meanAbsDiff(updated, want); diff > 1 {
+		t.Errorf("updated reconstruction differs from a fresh SVD by %.2f on average", diff)
+	}
+	stale := reconstructCachedSVD(mustCacheSVD(t, src, width, height, rank), rank)
+	if meanAbsDiff(stale, want) <= meanAbsDiff(updated, want) {
+		t.Error("the update did not move the reconstruction toward the edited image")
+	}
+}
+
+func mustCacheSVD(t *testing.T, data []uint8, width, height, rank int) *cachedSVD {
+	t.Helper()
+	cache, err := cacheSVD(data, width, height, rank)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cache
+}